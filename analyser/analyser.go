@@ -4,41 +4,265 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Analyser produces the stream of tokens to index for content
 type Analyser interface {
-	Analyse(content interface{}) ([]string, error)
+	Analyse(content interface{}) ([]Token, error)
 }
 
+// Token is a single term produced by an analysis chain
+// along with its position in the token stream
+type Token struct {
+	Term     string
+	Position int
+	// Start and End are the byte offsets of the text the
+	// token was produced from within the original content
+	Start int
+	End   int
+	Type  string
+	// Keyword tokens are not modified by stemmers
+	Keyword bool
+}
+
+// PositionIncrements returns the difference between the position of each
+// token and the position of the token before it. The first token is
+// relative to a position before the start of the stream, so a first token
+// at position 0 has an increment of 1. Tokens stacked at the same
+// position have an increment of 0 and removed tokens leave increments
+// greater than 1
+func PositionIncrements(tokens []Token) []int {
+	increments := make([]int, len(tokens))
+	previous := -1
+	for i, t := range tokens {
+		increments[i] = t.Position - previous
+		previous = t.Position
+	}
+	return increments
+}
+
+// CharFilter transforms text before it is tokenized, returning
+// the OffsetMap from the transformed text to content
+type CharFilter interface {
+	Filter(content string) (string, OffsetMap)
+}
+
+// ChunkedCharFilter is a CharFilter of markup that may be split between
+// the chunks of a reader. Pending returns the offset of markup at the end
+// of content which may be finished by the next chunk, otherwise the
+// length of content, so that the markup is filtered with the next chunk
+type ChunkedCharFilter interface {
+	CharFilter
+	Pending(content string) int
+}
+
+// TokenFilter transforms the stream of tokens produced by a Tokenizer
+type TokenFilter interface {
+	Filter(tokens []Token) []Token
+}
+
+// FullTextAnalyser is an analysis chain. Content is passed through each
+// CharFilter, split into tokens by the Tokenizer and then passed
+// through each TokenFilter in order
 type FullTextAnalyser struct {
-	Tokenizer Tokenizer
+	CharFilters  []CharFilter
+	Tokenizer    Tokenizer
+	TokenFilters []TokenFilter
 }
 
-func (a *FullTextAnalyser) Analyse(content interface{}) ([]string, error) {
+// Terms runs content through the analysis chain returning the terms produced
+func (a *FullTextAnalyser) Terms(content interface{}) ([]string, error) {
+	tokens, err := a.Analyse(content)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+	return terms, nil
+}
+
+// Analyse runs content through the analysis chain returning the tokens produced
+func (a *FullTextAnalyser) Analyse(content interface{}) ([]Token, error) {
+	var tokens []Token
+	err := a.AnalyseStream(content, func(token Token) bool {
+		tokens = append(tokens, token)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// chunkSize is the number of bytes of a reader analysed at a time
+const chunkSize = 64 * 1024
+
+// AnalyseStream runs content through the analysis chain calling emit with
+// each token produced, until emit returns false. The content of an
+// io.Reader is analysed in chunks as it is read, split after the last
+// line break or white space of each chunk, so that large content is not
+// held in memory. Markup at the end of a chunk which a ChunkedCharFilter
+// has not finished, such as an HTML tag, is analysed with the next chunk.
+// Tokens of a chunk are not combined with tokens of the next by token
+// filters such as shingle, nor is text matched across chunks by other
+// character filters
+func (a *FullTextAnalyser) AnalyseStream(content interface{}, emit func(token Token) bool) error {
+	var r io.Reader
 	switch content.(type) {
 	case string:
-		return a.Tokenizer.Tokenize(content.(string)), nil
-	case io.ReadCloser:
-		buf := new(bytes.Buffer)
-		_, err := buf.ReadFrom(content.(io.ReadCloser))
-		if err != nil {
-			return nil, err
-		}
-		return a.Tokenizer.Tokenize(buf.String()), nil
+		a.analyseChunk(content.(string), 0, 0, true, emit)
+		return nil
+	case io.Reader:
+		r = content.(io.Reader)
 	default:
-		return nil, errors.New("string or io.ReadCloser type required")
+		return errors.New("string or io.Reader type required")
+	}
+	buf := make([]byte, chunkSize)
+	// bytes in buf, offset of buf in content and position of the next chunk
+	n, offset, position := 0, 0, 0
+	for {
+		m, err := io.ReadFull(r, buf[n:])
+		n += m
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return err
+		}
+		end := n
+		if !eof {
+			end = chunkEnd(buf)
+		}
+		var ok bool
+		end, position, ok = a.analyseChunk(string(buf[:end]), offset, position, eof, emit)
+		if !ok || eof {
+			return nil
+		}
+		offset += end
+		n = copy(buf, buf[end:n])
 	}
 }
 
-type KeywordAnalyser struct{}
+// analyseChunk runs text found at offset of the content through the
+// analysis chain, with positions following position. Unless last, markup
+// which is not finished at the end of text is left for the next chunk. It
+// returns the length of text analysed, the position of the next chunk and
+// false when emit stopped the analysis
+func (a *FullTextAnalyser) analyseChunk(text string, offset int, position int, last bool, emit func(token Token) bool) (int, int, bool) {
+	filtered, offsets, end := a.charFilter(text, last)
+	if end < len(text) {
+		filtered, offsets, _ = a.charFilter(text[:end], true)
+	}
+	text = filtered
+	tokenizer := a.Tokenizer
+	if tokenizer == nil {
+		tokenizer = NewTokenizer()
+	}
+	tokens := tokenizer.Tokenize(text)
+	next := position
+	// offsets of tokens refer to the original content
+	for i := range tokens {
+		tokens[i].Start, tokens[i].End = offset+offsets.Start(tokens[i].Start), offset+offsets.End(tokens[i].End)
+		tokens[i].Position += position
+		if tokens[i].Position >= next {
+			next = tokens[i].Position + 1
+		}
+	}
+	for _, f := range a.TokenFilters {
+		tokens = f.Filter(tokens)
+	}
+	for _, t := range tokens {
+		if !emit(t) {
+			return end, 0, false
+		}
+		// token filters may add positions
+		if t.Position >= next {
+			next = t.Position + 1
+		}
+	}
+	return end, next, true
+}
+
+// charFilter runs text through the character filters returning the
+// filtered text, its OffsetMap and, unless last, the offset of the first
+// markup which a filter has not finished at the end of text, otherwise
+// the length of text. Markup starting text is not left, so that markup
+// longer than a chunk is filtered in parts
+func (a *FullTextAnalyser) charFilter(text string, last bool) (string, OffsetMap, int) {
+	var offsets OffsetMap
+	end := len(text)
+	for _, f := range a.CharFilters {
+		if c, ok := f.(ChunkedCharFilter); ok && !last {
+			if p := offsets.Start(c.Pending(text)); p > 0 && p < end {
+				end = p
+			}
+		}
+		var m OffsetMap
+		text, m = f.Filter(text)
+		offsets = offsets.Then(m)
+	}
+	return text, offsets, end
+}
+
+// chunkEnd returns the length of the part of a full buffer to analyse,
+// ending after the last line break, otherwise the last white space,
+// otherwise the last complete character
+func chunkEnd(buf []byte) int {
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		return i + 1
+	}
+	if i := bytes.LastIndexFunc(buf, unicode.IsSpace); i >= 0 {
+		_, size := utf8.DecodeRune(buf[i:])
+		return i + size
+	}
+	i := len(buf) - 1
+	for i > 0 && !utf8.RuneStart(buf[i]) {
+		i--
+	}
+	if utf8.FullRune(buf[i:]) {
+		return len(buf)
+	}
+	return i
+}
+
+// KeywordAnalyser produces a single token for each value, or the tokens
+// of each value produced by Tokenizer when set. Values are normalized by
+// passing them through each TokenFilter in order
+type KeywordAnalyser struct {
+	Tokenizer    Tokenizer
+	TokenFilters []TokenFilter
+}
 
-func (a *KeywordAnalyser) Analyse(content interface{}) ([]string, error) {
+func (a *KeywordAnalyser) Analyse(content interface{}) ([]Token, error) {
+	var values []string
 	switch content.(type) {
 	case string:
-		return []string{content.(string)}, nil
+		values = []string{content.(string)}
 	case []string:
-		return content.([]string), nil
+		values = content.([]string)
 	default:
 		return nil, errors.New("expecting string or []string")
 	}
+	tokens := make([]Token, 0, len(values))
+	for i, v := range values {
+		if a.Tokenizer == nil {
+			tokens = append(tokens, Token{Term: v, Position: i, Start: 0, End: len(v), Type: TypeWord})
+			continue
+		}
+		// the tokens of each value follow those of the previous value
+		position := 0
+		if len(tokens) > 0 {
+			position = tokens[len(tokens)-1].Position + 1
+		}
+		for _, t := range a.Tokenizer.Tokenize(v) {
+			t.Position += position
+			tokens = append(tokens, t)
+		}
+	}
+	for _, f := range a.TokenFilters {
+		tokens = f.Filter(tokens)
+	}
+	return tokens, nil
 }
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"github.com/richardjennings/invertedindex/test"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -14,18 +16,23 @@ func TestFullTextAnalyser_Analyse(t *testing.T) {
 
 	// with string
 	r, err := a.Analyse("a b c")
-	assert.Equal(t, []string{"a", "b", "c"}, r)
+	want := []Token{
+		{Term: "a", Position: 0, Start: 0, End: 1, Type: TypeAlphaNum},
+		{Term: "b", Position: 1, Start: 2, End: 3, Type: TypeAlphaNum},
+		{Term: "c", Position: 2, Start: 4, End: 5, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, r)
 	assert.Nil(t, err)
 
 	// with io.ReadCloser
-	r, err = a.Analyse(ioutil.NopCloser(bytes.NewBufferString("d e f")))
-	assert.Equal(t, []string{"d", "e", "f"}, r)
+	terms, err := a.Terms(ioutil.NopCloser(bytes.NewBufferString("d e f")))
+	assert.Equal(t, []string{"d", "e", "f"}, terms)
 	assert.Nil(t, err)
 
 	// with unsuported type
 	r, err = a.Analyse(1)
 	assert.Nil(t, r)
-	assert.Equal(t, errors.New("string or io.ReadCloser type required"), err)
+	assert.Equal(t, errors.New("string or io.Reader type required"), err)
 
 	// with failed io.ReadCloser read
 	r, err = a.Analyse(test.NewErrReadCloser())
@@ -38,16 +45,188 @@ func TestKeywordAnalyser_Analyse(t *testing.T) {
 
 	// with string
 	r, err := a.Analyse("a b c")
-	assert.Equal(t, []string{"a b c"}, r)
+	assert.Equal(t, []Token{{Term: "a b c", Position: 0, Start: 0, End: 5, Type: TypeWord}}, r)
 	assert.Nil(t, err)
 
 	// with []string
-	r, err = a.Analyse([]string{"d e f"})
-	assert.Equal(t, []string{"d e f"}, r)
+	r, err = a.Analyse([]string{"d e f", "g"})
+	want := []Token{
+		{Term: "d e f", Position: 0, Start: 0, End: 5, Type: TypeWord},
+		{Term: "g", Position: 1, Start: 0, End: 1, Type: TypeWord},
+	}
+	assert.Equal(t, want, r)
 	assert.Nil(t, err)
 
 	// with unsupported type
 	r, err = a.Analyse(1)
 	assert.Nil(t, r)
 	assert.Equal(t, errors.New("expecting string or []string"), err)
+
+	// with token filters
+	a = KeywordAnalyser{TokenFilters: []TokenFilter{LowercaseFilter{}}}
+	r, err = a.Analyse("A@B.com")
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{{Term: "a@b.com", Position: 0, Start: 0, End: 7, Type: TypeWord}}, r)
+
+	// with tokenizer
+	a = KeywordAnalyser{Tokenizer: PathHierarchyTokenizer{Delimiter: "/"}}
+	r, err = a.Analyse([]string{"/a/b", "/c"})
+	assert.Nil(t, err)
+	want = []Token{
+		{Term: "/a", Position: 0, Start: 0, End: 2, Type: TypeWord},
+		{Term: "/a/b", Position: 0, Start: 0, End: 4, Type: TypeWord},
+		{Term: "/c", Position: 1, Start: 0, End: 2, Type: TypeWord},
+	}
+	assert.Equal(t, want, r)
+}
+
+func TestPositionIncrements(t *testing.T) {
+	tokens := []Token{{Term: "a", Position: 0}, {Term: "b", Position: 2}, {Term: "c", Position: 2}, {Term: "d", Position: 3}}
+	assert.Equal(t, []int{1, 2, 0, 1}, PositionIncrements(tokens))
+	assert.Equal(t, []int{}, PositionIncrements(nil))
+}
+
+func TestFullTextAnalyser_AnalyseStream(t *testing.T) {
+	a := FullTextAnalyser{TokenFilters: []TokenFilter{LowercaseFilter{}}}
+
+	// content larger than a chunk, with a word straddling the first chunk
+	content := strings.Repeat("a", chunkSize-2) + " Bcd\nEf " + strings.Repeat("g ", chunkSize)
+	var tokens []Token
+	err := a.AnalyseStream(strings.NewReader(content), func(token Token) bool {
+		tokens = append(tokens, token)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, chunkSize+3, len(tokens))
+	assert.Equal(t, Token{Term: "bcd", Position: 1, Start: chunkSize - 1, End: chunkSize + 2, Type: TypeAlphaNum}, tokens[1])
+	assert.Equal(t, Token{Term: "ef", Position: 2, Start: chunkSize + 3, End: chunkSize + 5, Type: TypeAlphaNum}, tokens[2])
+	for i, token := range tokens {
+		assert.Equal(t, i, token.Position)
+		assert.Equal(t, token.Term, strings.ToLower(content[token.Start:token.End]))
+	}
+
+	// stopped by emit
+	count := 0
+	err = a.AnalyseStream(strings.NewReader(content), func(token Token) bool {
+		count++
+		return count < 3
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	// stop words removed at the end of a chunk leave a gap
+	a = FullTextAnalyser{TokenFilters: []TokenFilter{NewStopFilter([]string{"the"}, false)}}
+	content = strings.Repeat("a ", chunkSize/2-2) + "the\nb"
+	tokens, err = a.Analyse(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Equal(t, Token{Term: "b", Position: chunkSize/2 - 1, Start: chunkSize, End: chunkSize + 1, Type: TypeAlphaNum}, tokens[len(tokens)-1])
+
+	// a tag split between chunks is filtered with the next chunk
+	a = FullTextAnalyser{CharFilters: []CharFilter{NewHTMLStripFilter(nil)}}
+	content = strings.Repeat(" ", chunkSize-5) + "<a href=\"x\">b</a>"
+	tokens, err = a.Analyse(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{{Term: "b", Position: 0, Start: chunkSize + 7, End: chunkSize + 8, Type: TypeAlphaNum}}, tokens)
+
+	// with failed read
+	err = a.AnalyseStream(test.NewErrReadCloser(), func(token Token) bool { return true })
+	assert.Equal(t, errors.New("test error"), err)
+	a = FullTextAnalyser{}
+	err = a.AnalyseStream(test.NewErrReadCloser(), func(token Token) bool { return true })
+	assert.Equal(t, errors.New("test error"), err)
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestFullTextAnalyser_AnalyseStreamCharFilters(t *testing.T) {
+	a, err := NewAnalyser("custom", Config{"char_filter": "html_strip", "tokenizer": "standard"})
+	assert.Nil(t, err)
+
+	// a large body is analysed a chunk at a time, with markup split at
+	// white space between chunks
+	unit := `<p class="a b">Fish &amp;<script>var s = "x y";</script><b title="c d">chips</b></p> `
+	content := strings.Repeat(unit, 20*chunkSize/len(unit))
+	r := &countingReader{r: strings.NewReader(content)}
+	var tokens []Token
+	err = a.AnalyseStream(r, func(token Token) bool {
+		if len(tokens) == 0 {
+			assert.Equal(t, chunkSize, r.n)
+		}
+		tokens = append(tokens, token)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(content), r.n)
+	assert.Equal(t, 2*strings.Count(content, unit), len(tokens))
+	for i, token := range tokens {
+		want := "Fish"
+		if i%2 == 1 {
+			want = "chips"
+		}
+		assert.Equal(t, want, token.Term)
+		assert.Equal(t, want, content[token.Start:token.End])
+		assert.Equal(t, i, token.Position)
+	}
+
+	// an entity split between chunks without white space
+	a, err = NewAnalyser("custom", Config{"char_filter": "html_strip", "tokenizer": "whitespace"})
+	assert.Nil(t, err)
+	content = strings.Repeat("x", chunkSize-2) + "&amp; y"
+	tokens, err = a.Analyse(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{
+		{Term: strings.Repeat("x", chunkSize-2), Position: 0, Start: 0, End: chunkSize - 2, Type: TypeWord},
+		{Term: "&", Position: 1, Start: chunkSize - 2, End: chunkSize + 3, Type: TypeWord},
+		{Term: "y", Position: 2, Start: chunkSize + 4, End: chunkSize + 5, Type: TypeWord},
+	}, tokens)
+}
+
+func TestHTMLStripFilter_Pending(t *testing.T) {
+	f := NewHTMLStripFilter([]string{"b"})
+	for _, tcase := range []struct {
+		content string
+		want    int
+	}{
+		{"a <b>c</b> d", 12},
+		{"a &amp; b", 9},
+		{"a &am", 2},
+		{"a &#x2", 2},
+		{"a <", 2},
+		{"a < b", 5},
+		{"a <p class=\"x", 2},
+		{"a </p", 2},
+		{"a <!-- b", 2},
+		{"a <!-- b --> c <!", 15},
+		{"a <script>b</scr", 2},
+		{"a <style>b</style> c", 20},
+		{"a <b title=\"x", 2},
+	} {
+		assert.Equal(t, tcase.want, f.Pending(tcase.content), tcase.content)
+	}
+}
+
+func TestChunkEnd(t *testing.T) {
+	for _, tcase := range []struct {
+		buf  string
+		want int
+	}{
+		{"ab\ncd ef", 3},
+		{"ab cd", 3},
+		{"ab\u00a0cd", 4},
+		{"abcd", 4},
+		{"ab\xc3", 2},
+		{"ab\xc3\xa9", 4},
+	} {
+		assert.Equal(t, tcase.want, chunkEnd([]byte(tcase.buf)), tcase.buf)
+	}
 }
@@ -0,0 +1,228 @@
+package analyser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Analysis holds analysis components defined in index settings. Each
+// definition is the parameters of a built-in component of the given
+// type. Components are looked up by name in the definitions before
+// the built-in components
+type Analysis struct {
+	CharFilters map[string]Config `json:"char_filter"`
+	Tokenizers  map[string]Config `json:"tokenizer"`
+	Filters     map[string]Config `json:"filter"`
+	Analysers   map[string]Config `json:"analyzer"`
+	Normalizers map[string]Config `json:"normalizer"`
+}
+
+// Validate creates each of the defined components returning the first error
+func (a Analysis) Validate() error {
+	for name := range a.CharFilters {
+		if _, err := a.charFilter(name); err != nil {
+			return err
+		}
+	}
+	for name := range a.Tokenizers {
+		if _, err := a.tokenizer(name); err != nil {
+			return err
+		}
+	}
+	for name := range a.Filters {
+		if _, err := a.tokenFilter(name); err != nil {
+			return err
+		}
+	}
+	for name := range a.Analysers {
+		if _, err := a.NewAnalyser(name, nil); err != nil {
+			return err
+		}
+	}
+	for name := range a.Normalizers {
+		if _, err := a.NewNormalizer(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definition returns the type and parameters of a defined component
+func definition(defs map[string]Config, name string, def string) (string, Config, bool, error) {
+	cfg, ok := defs[name]
+	if !ok {
+		return "", nil, false, nil
+	}
+	typ, err := cfg.String("type", def)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if typ == "" {
+		return "", nil, false, fmt.Errorf("missing type for %s", name)
+	}
+	return typ, cfg, true, nil
+}
+
+// NewAnalyser creates the analyser name, either defined in settings or
+// built-in, using cfg for the parameters of built-in analysers. The
+// custom analyser builds a chain from the char_filter, tokenizer and
+// filter names in its parameters. Each component of the chain is
+// created from its own definition, or with its default parameters
+// when built-in. Defined analysers are custom unless another type is
+// given
+func (a Analysis) NewAnalyser(name string, cfg Config) (*FullTextAnalyser, error) {
+	typ, def, ok, err := definition(a.Analysers, name, "custom")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		name, cfg = typ, def
+	}
+	if name == "custom" {
+		return a.newCustomAnalyser(cfg)
+	}
+	f, ok := analysers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown analyser %s", name)
+	}
+	return f(cfg)
+}
+
+func (a Analysis) newCustomAnalyser(cfg Config) (*FullTextAnalyser, error) {
+	result := &FullTextAnalyser{}
+	name, err := cfg.String("tokenizer", "")
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, errors.New("custom analyser requires a tokenizer")
+	}
+	if result.Tokenizer, err = a.tokenizer(name); err != nil {
+		return nil, err
+	}
+
+	names, err := cfg.Strings("char_filter")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		c, err := a.charFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		result.CharFilters = append(result.CharFilters, c)
+	}
+
+	names, err = cfg.Strings("filter")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		f, err := a.tokenFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		result.TokenFilters = append(result.TokenFilters, f)
+	}
+	return result, nil
+}
+
+// NewNormalizer creates the normalizer name for keyword values, either
+// defined in settings or built-in. The custom normalizer applies the
+// filter names in its parameters, which are restricted to filters that
+// transform terms without splitting them. Defined normalizers are custom
+// unless another type is given
+func (a Analysis) NewNormalizer(name string, cfg Config) (*KeywordAnalyser, error) {
+	typ, def, ok, err := definition(a.Normalizers, name, "custom")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		name, cfg = typ, def
+	}
+	if name != "custom" {
+		f, ok := normalizers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown normalizer %s", name)
+		}
+		return f(cfg)
+	}
+	result := &KeywordAnalyser{}
+	names, err := cfg.Strings("filter")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		typ, _, ok, err := definition(a.Filters, name, "")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			typ = name
+		}
+		if !normalizerFilters[typ] {
+			return nil, fmt.Errorf("token filter %s cannot be used in a normalizer", name)
+		}
+		f, err := a.tokenFilter(name)
+		if err != nil {
+			return nil, err
+		}
+		result.TokenFilters = append(result.TokenFilters, f)
+	}
+	return result, nil
+}
+
+// charFilter creates the char filter name, either defined in settings
+// with its parameters or built-in with its default parameters
+func (a Analysis) charFilter(name string) (CharFilter, error) {
+	typ, cfg, ok, err := definition(a.CharFilters, name, "")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		name = typ
+	}
+	f, ok := charFilters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown char filter %s", name)
+	}
+	return f(cfg)
+}
+
+// NewTokenizer creates the tokenizer name, either defined in settings
+// with its parameters or built-in with its default parameters
+func (a Analysis) NewTokenizer(name string) (Tokenizer, error) {
+	return a.tokenizer(name)
+}
+
+func (a Analysis) tokenizer(name string) (Tokenizer, error) {
+	typ, cfg, ok, err := definition(a.Tokenizers, name, "")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		name = typ
+	}
+	f, ok := tokenizers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %s", name)
+	}
+	return f(cfg)
+}
+
+// tokenFilter creates the token filter name, either defined in settings
+// with its parameters or built-in with its default parameters
+func (a Analysis) tokenFilter(name string) (TokenFilter, error) {
+	typ, cfg, ok, err := definition(a.Filters, name, "")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		name = typ
+	}
+	f, ok := tokenFilters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown token filter %s", name)
+	}
+	return f(cfg)
+}
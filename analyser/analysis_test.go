@@ -0,0 +1,130 @@
+package analyser
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnalysis_NewAnalyser(t *testing.T) {
+	var a Analysis
+	err := json.Unmarshal([]byte(`{
+		"char_filter": {},
+		"tokenizer": {"grams": {"type": "ngram", "min_gram": 2, "max_gram": 2}},
+		"filter": {
+			"english_stop": {"type": "stop", "stopwords": "_english_"},
+			"product_synonyms": {"type": "synonym", "synonyms": ["laptop, notebook"]}
+		},
+		"analyzer": {
+			"products": {"tokenizer": "standard", "filter": ["lowercase", "english_stop", "product_synonyms"]},
+			"grams": {"type": "custom", "tokenizer": "grams"},
+			"stopped": {"type": "standard", "stopwords": ["foo"]}
+		}
+	}`), &a)
+	assert.Nil(t, err)
+	assert.Nil(t, a.Validate())
+
+	tcases := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"products", "The Laptop", []string{"laptop", "notebook"}},
+		{"grams", "abc", []string{"ab", "bc"}},
+		{"stopped", "Foo Bar", []string{"bar"}},
+		{"whitespace", "Foo Bar", []string{"Foo", "Bar"}},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := a.NewAnalyser(tc.name, nil)
+			assert.Nil(t, err)
+			terms, err := f.Terms(tc.content)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, terms)
+		})
+	}
+
+	// a field chain may use defined components
+	f, err := a.NewAnalyser("custom", Config{"tokenizer": "whitespace", "filter": "english_stop"})
+	assert.Nil(t, err)
+	terms, err := f.Terms("the cat")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cat"}, terms)
+
+	// parameters of a chain are not given to its components
+	f, err = a.NewAnalyser("custom", Config{"tokenizer": "whitespace", "filter": "stop", "stopwords": "cat"})
+	assert.Nil(t, err)
+	terms, err = f.Terms("the cat")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cat"}, terms)
+}
+
+func TestAnalysis_Validate(t *testing.T) {
+	tcases := []struct {
+		name     string
+		analysis Analysis
+		wantErr  error
+	}{
+		{"empty", Analysis{}, nil},
+		{"missing char filter type", Analysis{CharFilters: map[string]Config{"c": {}}}, errors.New("missing type for c")},
+		{"unknown char filter", Analysis{CharFilters: map[string]Config{"c": {"type": "magic"}}}, errors.New("unknown char filter magic")},
+		{"missing tokenizer type", Analysis{Tokenizers: map[string]Config{"t": {}}}, errors.New("missing type for t")},
+		{"unknown tokenizer", Analysis{Tokenizers: map[string]Config{"t": {"type": "magic"}}}, errors.New("unknown tokenizer magic")},
+		{"invalid tokenizer", Analysis{Tokenizers: map[string]Config{"t": {"type": "ngram", "min_gram": 3, "max_gram": 2}}}, errors.New("invalid min_gram or max_gram")},
+		{"missing filter type", Analysis{Filters: map[string]Config{"f": {}}}, errors.New("missing type for f")},
+		{"unknown filter", Analysis{Filters: map[string]Config{"f": {"type": "magic"}}}, errors.New("unknown token filter magic")},
+		{"invalid filter type", Analysis{Filters: map[string]Config{"f": {"type": 1}}}, errors.New("expected string for type")},
+		{"analyser without tokenizer", Analysis{Analysers: map[string]Config{"a": {}}}, errors.New("custom analyser requires a tokenizer")},
+		{"analyser unknown filter", Analysis{Analysers: map[string]Config{"a": {"tokenizer": "standard", "filter": "magic"}}}, errors.New("unknown token filter magic")},
+		{"unknown analyser", Analysis{Analysers: map[string]Config{"a": {"type": "magic"}}}, errors.New("unknown analyser magic")},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, tc.analysis.Validate())
+		})
+	}
+}
+
+func TestAnalysis_NewNormalizer(t *testing.T) {
+	a := Analysis{
+		Filters: map[string]Config{
+			"folding":  {"type": "asciifolding"},
+			"stemming": {"type": "stemmer"},
+		},
+		Normalizers: map[string]Config{
+			"email":  {"filter": []interface{}{"trim", "lowercase"}},
+			"folded": {"type": "custom", "filter": "lowercase,folding"},
+		},
+	}
+	assert.Nil(t, a.Validate())
+
+	tcases := []struct {
+		name    string
+		cfg     Config
+		value   string
+		want    string
+		wantErr error
+	}{
+		{"email", nil, " A@B.com ", "a@b.com", nil},
+		{"folded", nil, "Café", "cafe", nil},
+		{"lowercase", nil, "ABC", "abc", nil},
+		{"custom", Config{"filter": "asciifolding"}, "Café", "Cafe", nil},
+		{"custom", Config{"filter": "stemmer"}, "", "", errors.New("token filter stemmer cannot be used in a normalizer")},
+		{"custom", Config{"filter": "stemming"}, "", "", errors.New("token filter stemming cannot be used in a normalizer")},
+		{"magic", nil, "", "", errors.New("unknown normalizer magic")},
+	}
+	for _, tc := range tcases {
+		n, err := a.NewNormalizer(tc.name, tc.cfg)
+		assert.Equal(t, tc.wantErr, err, tc.name)
+		if err != nil {
+			continue
+		}
+		tokens, err := n.Analyse(tc.value)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.want, tokens[0].Term, tc.name)
+	}
+
+	a.Normalizers["broken"] = Config{"filter": "ngram"}
+	assert.Equal(t, errors.New("token filter ngram cannot be used in a normalizer"), a.Validate())
+}
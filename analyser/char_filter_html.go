@@ -0,0 +1,221 @@
+package analyser
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTagRegexp    = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9-]*)(?:\s[^>]*)?/?>`)
+	htmlOtherRegexp  = regexp.MustCompile(`^<[!?][^>]*>`)
+	htmlEntityRegexp = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});?`)
+
+	// the start of an entity which is not finished by the end of content
+	htmlEntityStartRegexp = regexp.MustCompile(`^&(?:#[xX]?[0-9a-fA-F]{0,7}|[a-zA-Z][a-zA-Z0-9]{0,31})?$`)
+)
+
+// tags that separate blocks of text, replaced by a new line so that the
+// text either side is not joined
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"td": true, "th": true, "tr": true, "ul": true,
+}
+
+// HTMLStripFilter removes HTML tags and decodes HTML entities. Block level
+// tags are replaced by a new line and the contents of script and style
+// elements are removed
+type HTMLStripFilter struct {
+	// EscapedTags are tag names that are not removed
+	EscapedTags map[string]bool
+}
+
+// NewHTMLStripFilter creates an HTMLStripFilter keeping the escapedTags
+func NewHTMLStripFilter(escapedTags []string) HTMLStripFilter {
+	f := HTMLStripFilter{EscapedTags: make(map[string]bool)}
+	for _, t := range escapedTags {
+		f.EscapedTags[strings.ToLower(t)] = true
+	}
+	return f
+}
+
+func (f HTMLStripFilter) Filter(content string) (string, OffsetMap) {
+	b := &offsetBuilder{}
+	close := -1
+	for i := 0; i < len(content); {
+		// copy text up to the next tag or entity
+		next := strings.IndexAny(content[i:], "<&")
+		if next != 0 {
+			if next < 0 {
+				next = len(content) - i
+			}
+			b.keep(content[i:i+next], i)
+			i += next
+			continue
+		}
+		if n, s, ok := f.markup(content[i:], tagEnd(content, i, &close)); ok {
+			if s != "" {
+				b.replace(s, i, i+n)
+			}
+			i += n
+			continue
+		}
+		b.keep(content[i:i+1], i)
+		i++
+	}
+	return b.build(len(content))
+}
+
+// Pending returns the offset of a tag, comment or entity at the end of
+// content which is not finished, or the length of content
+func (f HTMLStripFilter) Pending(content string) int {
+	close := -1
+	for i := 0; i < len(content); {
+		next := strings.IndexAny(content[i:], "<&")
+		if next < 0 {
+			break
+		}
+		i += next
+		end := tagEnd(content, i, &close)
+		if f.unfinished(content[i:], end) {
+			return i
+		}
+		if n, _, ok := f.markup(content[i:], end); ok {
+			i += n
+			continue
+		}
+		i++
+	}
+	return len(content)
+}
+
+// tagEnd returns the offset from i of the first > in content after i, or
+// of the end of content when there is none. close is the offset of the
+// last > found, so that content is searched once for each >
+func tagEnd(content string, i int, close *int) int {
+	if *close < i {
+		*close = len(content)
+		if c := strings.IndexByte(content[i:], '>'); c >= 0 {
+			*close = i + c
+		}
+	}
+	return *close - i
+}
+
+// unfinished reports whether content starts with markup that continues
+// after the end of content, where end is the offset of the first > in
+// content or the length of content
+func (f HTMLStripFilter) unfinished(content string, end int) bool {
+	if content[0] == '&' {
+		return htmlEntityStartRegexp.MatchString(content)
+	}
+	if strings.HasPrefix(content, "<!--") {
+		return !strings.Contains(content[4:], "-->")
+	}
+	if end == len(content) {
+		// a tag without a > so far
+		return len(content) == 1 || strings.IndexByte("!?/", content[1]) >= 0 || isASCIILetter(content[1])
+	}
+	m := htmlTagRegexp.FindStringSubmatch(content[:end+1])
+	if m == nil || m[1] != "" {
+		return false
+	}
+	name := strings.ToLower(m[2])
+	if f.EscapedTags[name] || name != "script" && name != "style" {
+		return false
+	}
+	// the contents of the element are removed up to its end tag
+	n := len(m[0])
+	endTag := indexEndTag(content[n:], name)
+	return endTag < 0 || strings.IndexByte(content[n+endTag:], '>') < 0
+}
+
+// markup matches a tag, comment or entity at the start of content returning
+// its length and the text replacing it. end is the offset of the first > in
+// content or the length of content, so that tags are matched only to it
+func (f HTMLStripFilter) markup(content string, end int) (int, string, bool) {
+	if content[0] == '&' {
+		m := htmlEntityRegexp.FindString(content)
+		if m == "" {
+			return 0, "", false
+		}
+		s := html.UnescapeString(m)
+		if s == m {
+			return 0, "", false
+		}
+		return len(m), s, true
+	}
+	if strings.HasPrefix(content, "<!--") {
+		end := strings.Index(content[4:], "-->")
+		if end < 0 {
+			return len(content), "", true
+		}
+		return end + 7, "", true
+	}
+	if end == len(content) {
+		return 0, "", false
+	}
+	if m := htmlOtherRegexp.FindString(content[:end+1]); m != "" {
+		return len(m), "", true
+	}
+	m := htmlTagRegexp.FindStringSubmatch(content[:end+1])
+	if m == nil {
+		return 0, "", false
+	}
+	name := strings.ToLower(m[2])
+	if f.EscapedTags[name] {
+		return 0, "", false
+	}
+	n := len(m[0])
+	if m[1] == "" && (name == "script" || name == "style") {
+		// remove the contents of the element
+		endTag := indexEndTag(content[n:], name)
+		if endTag < 0 {
+			return len(content), "", true
+		}
+		n += endTag
+		if close := strings.IndexByte(content[n:], '>'); close >= 0 {
+			n += close + 1
+		} else {
+			n = len(content)
+		}
+		return n, "", true
+	}
+	if htmlBlockTags[name] {
+		return n, "\n", true
+	}
+	return n, "", true
+}
+
+// isASCIILetter reports whether c is a letter from a to z in either case
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// indexEndTag returns the index of the first end tag of the element name
+// in content, ignoring case, or -1
+func indexEndTag(content string, name string) int {
+	for i := 0; ; {
+		j := strings.Index(content[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		i += j
+		if len(content)-i-2 >= len(name) && strings.EqualFold(content[i+2:i+2+len(name)], name) {
+			return i
+		}
+		i += 2
+	}
+}
+
+// htmlStripFilterFromConfig creates an HTMLStripFilter from the escaped_tags parameter
+func htmlStripFilterFromConfig(cfg Config) (CharFilter, error) {
+	tags, err := cfg.Strings("escaped_tags")
+	if err != nil {
+		return nil, err
+	}
+	return NewHTMLStripFilter(tags), nil
+}
@@ -0,0 +1,63 @@
+package analyser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestHTMLStripFilter_Filter(t *testing.T) {
+	for _, tcase := range []struct {
+		name    string
+		escaped []string
+		content string
+		want    string
+	}{
+		{"inline tags", nil, "<b>bold</b> <i class=\"x\">text</i>", "bold text"},
+		{"block tags", nil, "<p>one</p><p>two<br/>three</p>", "\none\n\ntwo\nthree\n"},
+		{"entities", nil, "fish &amp; chips &lt;3 &#39;a&#x27; caf&eacute;", "fish & chips <3 'a' café"},
+		{"unknown entity", nil, "a &foo; b", "a &foo; b"},
+		{"comments", nil, "a<!-- hidden -->b<!-- unterminated", "ab"},
+		{"script and style", nil, "a<script>var x = '<b>';</script>b<STYLE>p {}</STYLE>c", "abc"},
+		{"doctype", nil, "<!DOCTYPE html>text", "text"},
+		{"not a tag", nil, "a < b > c", "a < b > c"},
+		{"escaped tags", []string{"B"}, "<b>bold</b> <i>text</i>", "<b>bold</b> text"},
+	} {
+		have, _ := NewHTMLStripFilter(tcase.escaped).Filter(tcase.content)
+		assert.Equal(t, tcase.want, have, tcase.name)
+	}
+}
+
+// test text with many < which do not start tags is filtered in linear time
+func TestHTMLStripFilter_Unclosed(t *testing.T) {
+	f := NewHTMLStripFilter(nil)
+	for _, content := range []string{
+		strings.Repeat("a < b ", 200000),
+		strings.Repeat("a <b ", 200000),
+		strings.Repeat("<!x <?y ", 200000),
+	} {
+		have, _ := f.Filter(content)
+		assert.Equal(t, len(content), len(have))
+		f.Pending(content)
+	}
+	assert.Equal(t, 2, f.Pending(strings.Repeat("a <b ", 200000)))
+	assert.Equal(t, 1200000, f.Pending(strings.Repeat("a < b ", 200000)))
+	have, _ := f.Filter(strings.Repeat("a <b c ", 2) + "> d")
+	assert.Equal(t, "a  d", have)
+}
+
+func TestHTMLStripFilter_Offsets(t *testing.T) {
+	a, err := NewAnalyser("custom", Config{"char_filter": "html_strip", "tokenizer": "standard"})
+	assert.Nil(t, err)
+	content := "<p>Fish &amp; <b>chips</b></p>caf&eacute;"
+	tokens, err := a.Analyse(content)
+	assert.Nil(t, err)
+	want := []Token{
+		{Term: "Fish", Position: 0, Start: 3, End: 7, Type: TypeAlphaNum},
+		{Term: "chips", Position: 1, Start: 17, End: 22, Type: TypeAlphaNum},
+		{Term: "café", Position: 2, Start: 30, End: 41, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, tokens)
+	assert.Equal(t, "chips", content[17:22])
+	assert.Equal(t, "caf&eacute;", content[30:41])
+}
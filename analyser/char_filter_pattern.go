@@ -0,0 +1,52 @@
+package analyser
+
+import (
+	"errors"
+	"regexp"
+)
+
+// PatternReplaceFilter replaces text matching a regular expression.
+// Replacement may refer to submatches of the pattern using $1 or ${name}
+type PatternReplaceFilter struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// NewPatternReplaceFilter creates a PatternReplaceFilter compiling pattern
+func NewPatternReplaceFilter(pattern string, replacement string) (PatternReplaceFilter, error) {
+	if pattern == "" {
+		return PatternReplaceFilter{}, errors.New("pattern_replace requires a pattern")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return PatternReplaceFilter{}, err
+	}
+	return PatternReplaceFilter{Pattern: re, Replacement: replacement}, nil
+}
+
+func (f PatternReplaceFilter) Filter(content string) (string, OffsetMap) {
+	b := &offsetBuilder{}
+	last := 0
+	for _, m := range f.Pattern.FindAllStringSubmatchIndex(content, -1) {
+		b.keep(content[last:m[0]], last)
+		s := string(f.Pattern.ExpandString(nil, f.Replacement, content, m))
+		b.replace(s, m[0], m[1])
+		last = m[1]
+	}
+	b.keep(content[last:], last)
+	return b.build(len(content))
+}
+
+// patternReplaceFilterFromConfig creates a PatternReplaceFilter
+// from the pattern and replacement parameters
+func patternReplaceFilterFromConfig(cfg Config) (CharFilter, error) {
+	pattern, err := cfg.String("pattern", "")
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := cfg.String("replacement", "")
+	if err != nil {
+		return nil, err
+	}
+	return NewPatternReplaceFilter(pattern, replacement)
+}
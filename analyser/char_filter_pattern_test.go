@@ -0,0 +1,51 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPatternReplaceFilter_Filter(t *testing.T) {
+	f, err := NewPatternReplaceFilter(`(\d+)-(?P<b>\d+)`, "$1${b}")
+	assert.Nil(t, err)
+	have, m := f.Filter("call 123-456 now")
+	assert.Equal(t, "call 123456 now", have)
+	// the replacement maps to the matched text
+	assert.Equal(t, 5, m.Start(5))
+	assert.Equal(t, 12, m.End(11))
+	assert.Equal(t, 13, m.Start(12))
+
+	// chained with other char filters
+	analysis := Analysis{CharFilters: map[string]Config{
+		"digits": {"type": "pattern_replace", "pattern": `(\d+)-(\d+)`, "replacement": "$1$2"},
+	}}
+	a, err := analysis.NewAnalyser("custom", Config{"char_filter": "html_strip,digits", "tokenizer": "whitespace"})
+	assert.Nil(t, err)
+	tokens, err := a.Analyse("<b>123-456</b> x")
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{
+		{Term: "123456", Position: 0, Start: 3, End: 10, Type: TypeWord},
+		{Term: "x", Position: 1, Start: 15, End: 16, Type: TypeWord},
+	}, tokens)
+}
+
+func TestPatternReplaceFilterFromConfig(t *testing.T) {
+	for _, tcase := range []struct {
+		name string
+		cfg  Config
+		err  error
+	}{
+		{"valid", Config{"pattern": "a+"}, nil},
+		{"missing pattern", Config{}, errors.New("pattern_replace requires a pattern")},
+		{"invalid pattern", Config{"pattern": "("}, errors.New("error parsing regexp: missing closing ): `(`")},
+		{"invalid replacement", Config{"pattern": "a", "replacement": 1}, errors.New("expected string for replacement")},
+	} {
+		_, err := patternReplaceFilterFromConfig(tcase.cfg)
+		if tcase.err == nil {
+			assert.Nil(t, err, tcase.name)
+			continue
+		}
+		assert.Equal(t, tcase.err.Error(), err.Error(), tcase.name)
+	}
+}
@@ -0,0 +1,100 @@
+package analyser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Config holds the parameters of an analysis component. Values may come
+// from a JSON document or from a field mapping where they are strings
+type Config map[string]interface{}
+
+// String returns the string value of key or def if it is not set
+func (c Config) String(key string, def string) (string, error) {
+	v, ok := c[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string for %s", key)
+	}
+	return s, nil
+}
+
+// Strings returns the list value of key. Lists may be given
+// as an array or as a comma separated string
+func (c Config) Strings(key string) ([]string, error) {
+	v, ok := c[key]
+	if !ok {
+		return nil, nil
+	}
+	switch l := v.(type) {
+	case string:
+		var result []string
+		for _, s := range strings.Split(l, ",") {
+			s = strings.TrimSpace(s)
+			if s != "" {
+				result = append(result, s)
+			}
+		}
+		return result, nil
+	case []string:
+		return l, nil
+	case []interface{}:
+		result := make([]string, len(l))
+		for i, s := range l {
+			str, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected array of strings for %s", key)
+			}
+			result[i] = str
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected array of strings for %s", key)
+	}
+}
+
+// Bool returns the boolean value of key or def if it is not set
+func (c Config) Bool(key string, def bool) (bool, error) {
+	v, ok := c[key]
+	if !ok {
+		return def, nil
+	}
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		r, err := strconv.ParseBool(b)
+		if err != nil {
+			return false, fmt.Errorf("expected boolean for %s", key)
+		}
+		return r, nil
+	default:
+		return false, fmt.Errorf("expected boolean for %s", key)
+	}
+}
+
+// Int returns the integer value of key or def if it is not set
+func (c Config) Int(key string, def int) (int, error) {
+	v, ok := c[key]
+	if !ok {
+		return def, nil
+	}
+	switch i := v.(type) {
+	case int:
+		return i, nil
+	case float64:
+		if i == float64(int(i)) {
+			return int(i), nil
+		}
+	case string:
+		r, err := strconv.Atoi(i)
+		if err == nil {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("expected integer for %s", key)
+}
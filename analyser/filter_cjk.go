@@ -0,0 +1,145 @@
+package analyser
+
+import (
+	"fmt"
+	"golang.org/x/text/width"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	TypeDouble = "<DOUBLE>"
+	TypeSingle = "<SINGLE>"
+)
+
+// scripts of the token types combined by the CJKBigramFilter
+var cjkScripts = map[string]string{
+	TypeIdeographic: "han",
+	TypeHiragana:    "hiragana",
+	TypeKatakana:    "katakana",
+	TypeHangul:      "hangul",
+}
+
+// CJKBigramFilter replaces adjacent Chinese, Japanese and Korean characters
+// with overlapping bigrams, so that text written without spaces between
+// words can be searched. A character not adjacent to another is emitted
+// on its own. Tokens of other scripts are not changed
+type CJKBigramFilter struct {
+	// OutputUnigrams also emits each character at the position of its bigram
+	OutputUnigrams bool
+	// IgnoredScripts are scripts, one of han, hiragana, katakana
+	// or hangul, not combined into bigrams
+	IgnoredScripts map[string]bool
+}
+
+// NewCJKBigramFilter creates a CJKBigramFilter ignoring the scripts ignoredScripts
+func NewCJKBigramFilter(ignoredScripts []string, outputUnigrams bool) (CJKBigramFilter, error) {
+	f := CJKBigramFilter{OutputUnigrams: outputUnigrams, IgnoredScripts: make(map[string]bool)}
+	for _, s := range ignoredScripts {
+		known := false
+		for _, script := range cjkScripts {
+			known = known || script == s
+		}
+		if !known {
+			return CJKBigramFilter{}, fmt.Errorf("unknown script %s", s)
+		}
+		f.IgnoredScripts[s] = true
+	}
+	return f, nil
+}
+
+// cjkBigramFilterFromConfig creates a CJKBigramFilter from
+// the ignored_scripts and output_unigrams parameters
+func cjkBigramFilterFromConfig(cfg Config) (TokenFilter, error) {
+	scripts, err := cfg.Strings("ignored_scripts")
+	if err != nil {
+		return nil, err
+	}
+	unigrams, err := cfg.Bool("output_unigrams", false)
+	if err != nil {
+		return nil, err
+	}
+	return NewCJKBigramFilter(scripts, unigrams)
+}
+
+// cjkChar is a character of a CJK token with its offsets
+type cjkChar struct {
+	term  string
+	start int
+	end   int
+}
+
+func (f CJKBigramFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	// how far following tokens are moved by earlier bigrams
+	shift := 0
+	for i := 0; i < len(tokens); {
+		if !f.isCJK(tokens[i]) {
+			t := tokens[i]
+			t.Position += shift
+			result = append(result, t)
+			i++
+			continue
+		}
+		// the characters of adjacent CJK tokens
+		var chars []cjkChar
+		j := i
+		for ; j < len(tokens) && f.isCJK(tokens[j]); j++ {
+			t := tokens[j]
+			if j > i && (t.Position != tokens[j-1].Position+1 || t.Start != tokens[j-1].End) {
+				break
+			}
+			// offsets of characters are only known when the token is the original text
+			exact := t.End-t.Start == len(t.Term)
+			for k, r := range t.Term {
+				c := cjkChar{term: t.Term[k : k+utf8.RuneLen(r)], start: t.Start, end: t.End}
+				if exact {
+					c.start, c.end = t.Start+k, t.Start+k+len(c.term)
+				}
+				chars = append(chars, c)
+			}
+		}
+		pos := tokens[i].Position + shift
+		var emitted []Token
+		if len(chars) == 1 {
+			emitted = append(emitted, Token{Term: chars[0].term, Position: pos, Start: chars[0].start, End: chars[0].end, Type: TypeSingle})
+		} else {
+			for k := 0; k < len(chars); k++ {
+				if f.OutputUnigrams {
+					emitted = append(emitted, Token{Term: chars[k].term, Position: pos + k, Start: chars[k].start, End: chars[k].end, Type: TypeSingle})
+				}
+				if k+1 < len(chars) {
+					emitted = append(emitted, Token{Term: chars[k].term + chars[k+1].term, Position: pos + k, Start: chars[k].start, End: chars[k+1].end, Type: TypeDouble})
+				}
+			}
+		}
+		sort.SliceStable(emitted, func(a, b int) bool {
+			return emitted[a].Position < emitted[b].Position
+		})
+		result = append(result, emitted...)
+		// positions used by the characters in place of the tokens
+		used := len(chars) - 1
+		if f.OutputUnigrams || used == 0 {
+			used++
+		}
+		shift += used - (j - i)
+		i = j
+	}
+	return result
+}
+
+func (f CJKBigramFilter) isCJK(t Token) bool {
+	script, ok := cjkScripts[t.Type]
+	return ok && !f.IgnoredScripts[script]
+}
+
+// CJKWidthFilter converts full width ASCII characters to their usual
+// width and half width Katakana to full width
+type CJKWidthFilter struct{}
+
+func (f CJKWidthFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = width.Fold.String(tokens[i].Term)
+	}
+	return tokens
+}
@@ -0,0 +1,81 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCJKBigramFilter_Filter(t *testing.T) {
+	f, err := NewCJKBigramFilter(nil, false)
+	assert.Nil(t, err)
+	have := f.Filter(StandardTokenizer{}.Tokenize("東京都 in Tokyo"))
+	want := []Token{
+		{Term: "東京", Position: 0, Start: 0, End: 6, Type: TypeDouble},
+		{Term: "京都", Position: 1, Start: 3, End: 9, Type: TypeDouble},
+		{Term: "in", Position: 2, Start: 10, End: 12, Type: TypeAlphaNum},
+		{Term: "Tokyo", Position: 3, Start: 13, End: 18, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, have)
+
+	// unigrams
+	f.OutputUnigrams = true
+	have = f.Filter(StandardTokenizer{}.Tokenize("東京 a"))
+	want = []Token{
+		{Term: "東", Position: 0, Start: 0, End: 3, Type: TypeSingle},
+		{Term: "東京", Position: 0, Start: 0, End: 6, Type: TypeDouble},
+		{Term: "京", Position: 1, Start: 3, End: 6, Type: TypeSingle},
+		{Term: "a", Position: 2, Start: 7, End: 8, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, have)
+
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	for _, tcase := range []struct {
+		name    string
+		ignored []string
+		content string
+		want    []string
+	}{
+		{"single", nil, "a 東 b", []string{"a", "東", "b"}},
+		{"separated runs", nil, "東京 大阪", []string{"東京", "大阪"}},
+		{"mixed scripts", nil, "日本のテレビ", []string{"日本", "本の", "のテ", "テレ", "レビ"}},
+		{"hangul", nil, "한국어 사전", []string{"한국", "국어", "사전"}},
+		{"ignored script", []string{"hangul"}, "한국어 東京", []string{"한국어", "東京"}},
+		{"latin", nil, "full text", []string{"full", "text"}},
+		{"empty", nil, "", nil},
+	} {
+		f, err := NewCJKBigramFilter(tcase.ignored, false)
+		assert.Nil(t, err)
+		assert.Equal(t, tcase.want, terms(f.Filter(StandardTokenizer{}.Tokenize(tcase.content))), tcase.name)
+	}
+
+	// following positions are shifted
+	f = CJKBigramFilter{}
+	have = f.Filter(StandardTokenizer{}.Tokenize("東京都 a"))
+	assert.Equal(t, []int{1, 1, 1}, PositionIncrements(have))
+	f.OutputUnigrams = true
+	have = f.Filter(StandardTokenizer{}.Tokenize("東京都 a"))
+	assert.Equal(t, []int{1, 0, 1, 0, 1, 1}, PositionIncrements(have))
+
+	_, err = NewCJKBigramFilter([]string{"latin"}, false)
+	assert.Equal(t, errors.New("unknown script latin"), err)
+}
+
+func TestCJKWidthFilter_Filter(t *testing.T) {
+	have := CJKWidthFilter{}.Filter([]Token{{Term: "ＡＢＣ１"}, {Term: "ｶﾀｶﾅ"}})
+	assert.Equal(t, []Token{{Term: "ABC1"}, {Term: "カタカナ"}}, have)
+}
+
+func TestCJKAnalyser(t *testing.T) {
+	a, err := NewAnalyser("cjk", nil)
+	assert.Nil(t, err)
+	terms, err := a.Terms("東京のＴｏｗｅｒ and the Sky")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"東京", "京の", "tower", "sky"}, terms)
+}
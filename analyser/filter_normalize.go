@@ -0,0 +1,115 @@
+package analyser
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// LowercaseFilter converts the terms of tokens to lower case
+type LowercaseFilter struct{}
+
+func (f LowercaseFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = strings.ToLower(tokens[i].Term)
+	}
+	return tokens
+}
+
+// TrimFilter removes leading and trailing white space from the terms of tokens
+type TrimFilter struct{}
+
+func (f TrimFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = strings.TrimSpace(tokens[i].Term)
+	}
+	return tokens
+}
+
+// NormalizationFilter converts the terms of tokens to a Unicode normalization form
+type NormalizationFilter struct {
+	Form norm.Form
+}
+
+func (f NormalizationFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = f.Form.String(tokens[i].Term)
+	}
+	return tokens
+}
+
+// characters without a canonical decomposition to ASCII
+var asciiFoldings = map[rune]string{
+	'Æ': "AE", 'æ': "ae", 'Ð': "D", 'ð': "d", 'Đ': "D", 'đ': "d",
+	'Ħ': "H", 'ħ': "h", 'ı': "i", 'Ĳ': "IJ", 'ĳ': "ij", 'ĸ': "q",
+	'Ŀ': "L", 'ŀ': "l", 'Ł': "L", 'ł': "l", 'Ŋ': "N", 'ŋ': "n",
+	'Ø': "O", 'ø': "o", 'Œ': "OE", 'œ': "oe", 'ß': "ss", 'Þ': "TH",
+	'þ': "th", 'Ŧ': "T", 'ŧ': "t", 'ƒ': "f", 'ſ': "s",
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '“': "\"", '”': "\"",
+	'„': "\"", '‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-",
+}
+
+// ASCIIFoldingFilter converts letters, numbers and symbols outside of the
+// Basic Latin block to their ASCII equivalent where one exists, for example
+// removing diacritics so that café becomes cafe
+type ASCIIFoldingFilter struct {
+	// PreserveOriginal also emits the unfolded token at the same position
+	PreserveOriginal bool
+}
+
+func (f ASCIIFoldingFilter) Filter(tokens []Token) []Token {
+	result := tokens[:0:0]
+	for _, token := range tokens {
+		folded := foldASCII(token.Term)
+		if f.PreserveOriginal && folded != token.Term {
+			result = append(result, token)
+		}
+		token.Term = folded
+		result = append(result, token)
+	}
+	return result
+}
+
+func foldASCII(term string) string {
+	ascii := true
+	for i := 0; i < len(term); i++ {
+		if term[i] > unicode.MaxASCII {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return term
+	}
+	var b strings.Builder
+	for _, r := range term {
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+		case r >= '\u0300' && r <= '\u036f':
+			// drop combining diacritical marks
+		case asciiFoldings[r] != "":
+			b.WriteString(asciiFoldings[r])
+		default:
+			b.WriteString(foldRune(r))
+		}
+	}
+	return b.String()
+}
+
+// foldRune returns the ASCII compatibility decomposition of r
+// without diacritics, or r if it has no ASCII equivalent
+func foldRune(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		switch {
+		case d <= unicode.MaxASCII:
+			b.WriteRune(d)
+		case d >= '\u0300' && d <= '\u036f':
+			// drop combining diacritical marks
+		default:
+			return string(r)
+		}
+	}
+	return b.String()
+}
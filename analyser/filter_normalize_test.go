@@ -0,0 +1,64 @@
+package analyser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/unicode/norm"
+	"testing"
+)
+
+func TestLowercaseFilter_Filter(t *testing.T) {
+	have := LowercaseFilter{}.Filter([]Token{{Term: "Café", Position: 0}, {Term: "ÉTÉ", Position: 1}})
+	assert.Equal(t, []Token{{Term: "café", Position: 0}, {Term: "été", Position: 1}}, have)
+}
+
+func TestNormalizationFilter_Filter(t *testing.T) {
+	// decomposed e followed by combining acute accent
+	have := NormalizationFilter{Form: norm.NFC}.Filter([]Token{{Term: "cafe\u0301"}})
+	assert.Equal(t, []Token{{Term: "café"}}, have)
+
+	// compatibility characters
+	have = NormalizationFilter{Form: norm.NFKC}.Filter([]Token{{Term: "ﬁ①"}})
+	assert.Equal(t, []Token{{Term: "fi1"}}, have)
+}
+
+func TestASCIIFoldingFilter_Filter(t *testing.T) {
+	for _, tcase := range []struct {
+		term string
+		want string
+	}{
+		{"cafe", "cafe"},
+		{"café", "cafe"},
+		{"cafe\u0301", "cafe"},
+		{"Ærøskøbing", "AEroskobing"},
+		{"straße", "strasse"},
+		{"Łódź", "Lodz"},
+		{"ﬁne", "fine"},
+		{"한국", "한국"},
+		{"中文", "中文"},
+	} {
+		have := ASCIIFoldingFilter{}.Filter([]Token{{Term: tcase.term}})
+		assert.Equal(t, []Token{{Term: tcase.want}}, have, tcase.term)
+	}
+
+	// preserve original
+	have := ASCIIFoldingFilter{PreserveOriginal: true}.Filter([]Token{{Term: "café", Position: 0}, {Term: "au", Position: 1}})
+	want := []Token{{Term: "café", Position: 0}, {Term: "cafe", Position: 0}, {Term: "au", Position: 1}}
+	assert.Equal(t, want, have)
+}
+
+func TestNewAnalyser_Folding(t *testing.T) {
+	a, err := NewAnalyser("custom", Config{"tokenizer": "standard", "filter": "nfc,lowercase,asciifolding"})
+	assert.Nil(t, err)
+	r, err := a.Terms("Le Café, l'été")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"le", "cafe", "l'ete"}, r)
+
+	analysis := Analysis{Filters: map[string]Config{"folding": {"type": "asciifolding", "preserve_original": "maybe"}}}
+	_, err = analysis.NewAnalyser("custom", Config{"tokenizer": "standard", "filter": "folding"})
+	assert.NotNil(t, err)
+}
+
+func TestTrimFilter_Filter(t *testing.T) {
+	have := TrimFilter{}.Filter([]Token{{Term: " a b\t"}, {Term: "c"}})
+	assert.Equal(t, []Token{{Term: "a b"}, {Term: "c"}}, have)
+}
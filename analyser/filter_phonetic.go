@@ -0,0 +1,87 @@
+package analyser
+
+import (
+	"fmt"
+)
+
+// PhoneticFilter replaces tokens with codes representing how they sound,
+// so that names such as Stroustrup match misspellings like Strostrup.
+// Encoders may produce more than one code, which are stacked at the
+// position of the token. Tokens without a code are not changed
+type PhoneticFilter struct {
+	Encode func(term string) []string
+	// Replace emits only the codes, otherwise the
+	// codes are stacked with the original token
+	Replace bool
+}
+
+// NewPhoneticFilter creates a PhoneticFilter for encoder, either soundex
+// or double_metaphone. Double Metaphone codes are at most maxCodeLen long
+func NewPhoneticFilter(encoder string, maxCodeLen int, replace bool) (PhoneticFilter, error) {
+	f := PhoneticFilter{Replace: replace}
+	switch encoder {
+	case "soundex":
+		f.Encode = func(term string) []string {
+			if code := Soundex(term); code != "" {
+				return []string{code}
+			}
+			return nil
+		}
+	case "double_metaphone":
+		if maxCodeLen < 1 {
+			return PhoneticFilter{}, fmt.Errorf("invalid max_code_len %d", maxCodeLen)
+		}
+		f.Encode = func(term string) []string {
+			primary, alternate := DoubleMetaphone(term, maxCodeLen)
+			var codes []string
+			if primary != "" {
+				codes = append(codes, primary)
+			}
+			if alternate != "" && alternate != primary {
+				codes = append(codes, alternate)
+			}
+			return codes
+		}
+	default:
+		return PhoneticFilter{}, fmt.Errorf("unknown phonetic encoder %s", encoder)
+	}
+	return f, nil
+}
+
+// phoneticFilterFromConfig creates a PhoneticFilter from the encoder,
+// by default double_metaphone, max_code_len, by default 4, and replace
+// parameters
+func phoneticFilterFromConfig(cfg Config) (TokenFilter, error) {
+	encoder, err := cfg.String("encoder", "double_metaphone")
+	if err != nil {
+		return nil, err
+	}
+	maxCodeLen, err := cfg.Int("max_code_len", 4)
+	if err != nil {
+		return nil, err
+	}
+	replace, err := cfg.Bool("replace", true)
+	if err != nil {
+		return nil, err
+	}
+	return NewPhoneticFilter(encoder, maxCodeLen, replace)
+}
+
+func (f PhoneticFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	for _, t := range tokens {
+		codes := f.Encode(t.Term)
+		if len(codes) == 0 || !f.Replace {
+			result = append(result, t)
+		}
+		for _, code := range codes {
+			if code == t.Term && !f.Replace {
+				continue
+			}
+			c := t
+			c.Term = code
+			result = append(result, c)
+		}
+	}
+	return result
+}
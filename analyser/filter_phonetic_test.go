@@ -0,0 +1,40 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPhoneticFilter_Filter(t *testing.T) {
+	f, err := NewPhoneticFilter("double_metaphone", 4, true)
+	assert.Nil(t, err)
+	have := f.Filter(WhitespaceTokenizer{}.Tokenize("smith 42 stroustrup"))
+	want := []Token{
+		{Term: "SM0", Position: 0, Start: 0, End: 5, Type: TypeWord},
+		{Term: "XMT", Position: 0, Start: 0, End: 5, Type: TypeWord},
+		{Term: "42", Position: 1, Start: 6, End: 8, Type: TypeWord},
+		{Term: "STRS", Position: 2, Start: 9, End: 19, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	// keep the original
+	f, err = NewPhoneticFilter("soundex", 0, false)
+	assert.Nil(t, err)
+	have = f.Filter(WhitespaceTokenizer{}.Tokenize("robert 42"))
+	want = []Token{
+		{Term: "robert", Position: 0, Start: 0, End: 6, Type: TypeWord},
+		{Term: "R163", Position: 0, Start: 0, End: 6, Type: TypeWord},
+		{Term: "42", Position: 1, Start: 7, End: 9, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	_, err = NewPhoneticFilter("caverphone", 4, true)
+	assert.Equal(t, errors.New("unknown phonetic encoder caverphone"), err)
+	_, err = NewPhoneticFilter("double_metaphone", 0, true)
+	assert.Equal(t, errors.New("invalid max_code_len 0"), err)
+
+	tf, err := phoneticFilterFromConfig(Config{"encoder": "double_metaphone", "max_code_len": "6"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{{Term: "STRSTR"}}, tf.Filter([]Token{{Term: "stroustrup"}}))
+}
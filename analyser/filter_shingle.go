@@ -0,0 +1,120 @@
+package analyser
+
+import (
+	"errors"
+	"strings"
+)
+
+const TypeShingle = "shingle"
+
+// ShingleFilter adds word n-grams, shingles, of between MinSize and MaxSize
+// consecutive tokens at the position of their first token. Positions left
+// empty, for example by a StopFilter, are filled with FillerToken. Where
+// tokens are stacked at the same position only the first is used in shingles
+type ShingleFilter struct {
+	MinSize   int
+	MaxSize   int
+	Separator string
+	// FillerToken represents an empty position within a shingle
+	FillerToken string
+	// OutputUnigrams also emits the original tokens
+	OutputUnigrams bool
+	// OutputUnigramsIfNoShingles emits the original tokens
+	// when no shingles are produced
+	OutputUnigramsIfNoShingles bool
+}
+
+// NewShingleFilter creates a ShingleFilter validating the shingle sizes
+func NewShingleFilter(minSize int, maxSize int) (ShingleFilter, error) {
+	if minSize < 2 || maxSize < minSize {
+		return ShingleFilter{}, errors.New("invalid min_shingle_size or max_shingle_size")
+	}
+	return ShingleFilter{MinSize: minSize, MaxSize: maxSize, Separator: " ", FillerToken: "_", OutputUnigrams: true}, nil
+}
+
+// shingleFilterFromConfig creates a ShingleFilter from the min_shingle_size,
+// max_shingle_size, output_unigrams, output_unigrams_if_no_shingles,
+// token_separator and filler_token parameters
+func shingleFilterFromConfig(cfg Config) (TokenFilter, error) {
+	minSize, err := cfg.Int("min_shingle_size", 2)
+	if err != nil {
+		return nil, err
+	}
+	maxSize, err := cfg.Int("max_shingle_size", 2)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewShingleFilter(minSize, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if f.OutputUnigrams, err = cfg.Bool("output_unigrams", true); err != nil {
+		return nil, err
+	}
+	if f.OutputUnigramsIfNoShingles, err = cfg.Bool("output_unigrams_if_no_shingles", false); err != nil {
+		return nil, err
+	}
+	if f.Separator, err = cfg.String("token_separator", " "); err != nil {
+		return nil, err
+	}
+	if f.FillerToken, err = cfg.String("filler_token", "_"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f ShingleFilter) Filter(tokens []Token) []Token {
+	if len(tokens) == 0 {
+		return tokens
+	}
+	// the first token at each position from the first position,
+	// positions without a token are nil
+	first := tokens[0].Position
+	slots := make([]*Token, tokens[len(tokens)-1].Position-first+1)
+	for i := range tokens {
+		if slot := &slots[tokens[i].Position-first]; *slot == nil {
+			*slot = &tokens[i]
+		}
+	}
+
+	var result []Token
+	shingles := 0
+	for i := 0; i < len(tokens); {
+		// unigrams at this position
+		pos := tokens[i].Position
+		j := i
+		for ; j < len(tokens) && tokens[j].Position == pos; j++ {
+			if f.OutputUnigrams {
+				result = append(result, tokens[j])
+			}
+		}
+		i = j
+
+		// shingles starting at this position ending with a token
+		start := pos - first
+		words := []string{slots[start].Term}
+		for n := 2; n <= f.MaxSize && start+n <= len(slots); n++ {
+			last := slots[start+n-1]
+			if last == nil {
+				words = append(words, f.FillerToken)
+				continue
+			}
+			words = append(words, last.Term)
+			if n < f.MinSize {
+				continue
+			}
+			result = append(result, Token{
+				Term:     strings.Join(words, f.Separator),
+				Position: pos,
+				Start:    slots[start].Start,
+				End:      last.End,
+				Type:     TypeShingle,
+			})
+			shingles++
+		}
+	}
+	if shingles == 0 && !f.OutputUnigrams && f.OutputUnigramsIfNoShingles {
+		return tokens
+	}
+	return result
+}
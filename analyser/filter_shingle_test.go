@@ -0,0 +1,77 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestShingleFilter_Filter(t *testing.T) {
+	f, err := NewShingleFilter(2, 2)
+	assert.Nil(t, err)
+	have := f.Filter(WhitespaceTokenizer{}.Tokenize("full text search"))
+	want := []Token{
+		{Term: "full", Position: 0, Start: 0, End: 4, Type: TypeWord},
+		{Term: "full text", Position: 0, Start: 0, End: 9, Type: TypeShingle},
+		{Term: "text", Position: 1, Start: 5, End: 9, Type: TypeWord},
+		{Term: "text search", Position: 1, Start: 5, End: 16, Type: TypeShingle},
+		{Term: "search", Position: 2, Start: 10, End: 16, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	for _, tcase := range []struct {
+		name    string
+		filter  ShingleFilter
+		content string
+		want    []string
+	}{
+		{"without unigrams", ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " "}, "a b c", []string{"a b", "b c"}},
+		{"sizes", ShingleFilter{MinSize: 2, MaxSize: 3, Separator: "_"}, "a b c", []string{"a_b", "a_b_c", "b_c"}},
+		{"min size", ShingleFilter{MinSize: 3, MaxSize: 3, Separator: " "}, "a b c d", []string{"a b c", "b c d"}},
+		{"single token", ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " "}, "a", nil},
+		{"unigrams if no shingles", ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " ", OutputUnigramsIfNoShingles: true}, "a", []string{"a"}},
+		{"empty", ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " ", OutputUnigrams: true}, "", nil},
+	} {
+		have := tcase.filter.Filter(WhitespaceTokenizer{}.Tokenize(tcase.content))
+		assert.Equal(t, tcase.want, terms(have), tcase.name)
+	}
+
+	// positions left by stop words are filled
+	tokens := NewStopFilter([]string{"of"}, false).Filter(WhitespaceTokenizer{}.Tokenize("lord of rings"))
+	have = ShingleFilter{MinSize: 2, MaxSize: 3, Separator: " ", FillerToken: "_"}.Filter(tokens)
+	assert.Equal(t, []string{"lord _ rings"}, terms(have))
+
+	// stacked tokens use the first token at a position
+	tokens = []Token{{Term: "a", Position: 0}, {Term: "b", Position: 1}, {Term: "c", Position: 1}}
+	have = ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " ", OutputUnigrams: true}.Filter(tokens)
+	assert.Equal(t, []string{"a", "a b", "b", "c"}, terms(have))
+}
+
+func TestShingleFilterFromConfig(t *testing.T) {
+	f, err := shingleFilterFromConfig(Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, ShingleFilter{MinSize: 2, MaxSize: 2, Separator: " ", FillerToken: "_", OutputUnigrams: true}, f)
+
+	f, err = shingleFilterFromConfig(Config{
+		"min_shingle_size":               "2",
+		"max_shingle_size":               "3",
+		"output_unigrams":                "false",
+		"output_unigrams_if_no_shingles": "true",
+		"token_separator":                "",
+		"filler_token":                   "",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ShingleFilter{MinSize: 2, MaxSize: 3, OutputUnigramsIfNoShingles: true}, f)
+
+	_, err = shingleFilterFromConfig(Config{"min_shingle_size": 1})
+	assert.Equal(t, errors.New("invalid min_shingle_size or max_shingle_size"), err)
+	_, err = shingleFilterFromConfig(Config{"max_shingle_size": "x"})
+	assert.Equal(t, errors.New("expected integer for max_shingle_size"), err)
+}
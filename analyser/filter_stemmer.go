@@ -0,0 +1,69 @@
+package analyser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// built-in stemmers by language
+var stemmers = map[string]func(string) string{
+	"english": StemEnglish,
+	"porter2": StemEnglish,
+}
+
+// StemmerFilter reduces the terms of tokens to their stem.
+// Tokens marked as keywords are not stemmed
+type StemmerFilter struct {
+	Stem func(term string) string
+}
+
+// NewStemmerFilter creates a StemmerFilter for language
+func NewStemmerFilter(language string) (StemmerFilter, error) {
+	stem, ok := stemmers[language]
+	if !ok {
+		return StemmerFilter{}, fmt.Errorf("unknown stemmer language %s", language)
+	}
+	return StemmerFilter{Stem: stem}, nil
+}
+
+func (f StemmerFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		if !tokens[i].Keyword {
+			tokens[i].Term = f.Stem(tokens[i].Term)
+		}
+	}
+	return tokens
+}
+
+// KeywordMarkerFilter marks tokens as keywords
+// protecting them from modification by stemmers
+type KeywordMarkerFilter struct {
+	Keywords   map[string]struct{}
+	IgnoreCase bool
+}
+
+// NewKeywordMarkerFilter creates a KeywordMarkerFilter protecting keywords
+func NewKeywordMarkerFilter(keywords []string, ignoreCase bool) KeywordMarkerFilter {
+	f := KeywordMarkerFilter{Keywords: make(map[string]struct{}), IgnoreCase: ignoreCase}
+	var t struct{}
+	for _, k := range keywords {
+		if ignoreCase {
+			k = strings.ToLower(k)
+		}
+		f.Keywords[k] = t
+	}
+	return f
+}
+
+func (f KeywordMarkerFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		term := tokens[i].Term
+		if f.IgnoreCase {
+			term = strings.ToLower(term)
+		}
+		if _, ok := f.Keywords[term]; ok {
+			tokens[i].Keyword = true
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,98 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStemmerFilter_Filter(t *testing.T) {
+	f, err := NewStemmerFilter("english")
+	assert.Nil(t, err)
+	have := f.Filter([]Token{{Term: "running"}, {Term: "runs", Keyword: true}})
+	assert.Equal(t, []Token{{Term: "run"}, {Term: "runs", Keyword: true}}, have)
+
+	_, err = NewStemmerFilter("klingon")
+	assert.Equal(t, errors.New("unknown stemmer language klingon"), err)
+}
+
+func TestKeywordMarkerFilter_Filter(t *testing.T) {
+	have := NewKeywordMarkerFilter([]string{"iPhones"}, false).Filter([]Token{{Term: "iPhones"}, {Term: "iphones"}})
+	assert.Equal(t, []Token{{Term: "iPhones", Keyword: true}, {Term: "iphones"}}, have)
+
+	have = NewKeywordMarkerFilter([]string{"iPhones"}, true).Filter([]Token{{Term: "IPHONES"}})
+	assert.Equal(t, []Token{{Term: "IPHONES", Keyword: true}}, have)
+}
+
+func TestNewAnalyser_Stemming(t *testing.T) {
+	for _, tcase := range []struct {
+		name     string
+		analyser string
+		cfg      Config
+		filters  map[string]Config
+		content  string
+		want     []string
+		err      error
+	}{
+		{
+			"english analyser",
+			"english", nil, nil,
+			"The Runners were running runs",
+			[]string{"runner", "were", "run", "run"},
+			nil,
+		},
+		{
+			"english analyser stem exclusion",
+			"english", Config{"stem_exclusion": "Galaxies"}, nil,
+			"galaxies and cookies",
+			[]string{"galaxies", "cooki"},
+			nil,
+		},
+		{
+			"custom chain",
+			"custom", Config{"tokenizer": "standard", "filter": "lowercase,protected,stemmer"},
+			map[string]Config{"protected": {"type": "keyword_marker", "keywords": "galaxies"}},
+			"Galaxies and cookies",
+			[]string{"galaxies", "and", "cooki"},
+			nil,
+		},
+		{
+			"snowball language",
+			"custom", Config{"tokenizer": "standard", "filter": "english_snowball"},
+			map[string]Config{"english_snowball": {"type": "snowball", "language": "English"}},
+			"cookies",
+			[]string{"cooki"},
+			nil,
+		},
+		{
+			"unknown language",
+			"custom", Config{"tokenizer": "standard", "filter": "klingon_stemmer"},
+			map[string]Config{"klingon_stemmer": {"type": "stemmer", "language": "klingon"}},
+			"", nil,
+			errors.New("unknown stemmer language klingon"),
+		},
+		{
+			"invalid keywords",
+			"custom", Config{"tokenizer": "standard", "filter": "protected"},
+			map[string]Config{"protected": {"type": "keyword_marker", "keywords": 1}},
+			"", nil,
+			errors.New("expected array of strings for keywords"),
+		},
+		{
+			"invalid stem exclusion",
+			"english", Config{"stem_exclusion": 1}, nil,
+			"", nil,
+			errors.New("expected array of strings for stem_exclusion"),
+		},
+	} {
+		analysis := Analysis{Filters: tcase.filters}
+		a, err := analysis.NewAnalyser(tcase.analyser, tcase.cfg)
+		assert.Equal(t, tcase.err, err, tcase.name)
+		if err != nil {
+			continue
+		}
+		r, err := a.Terms(tcase.content)
+		assert.Nil(t, err)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+}
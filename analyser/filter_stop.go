@@ -0,0 +1,75 @@
+package analyser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// built-in stop word lists by name
+var stopWords = map[string][]string{
+	"_english_": {
+		"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "is", "it",
+		"no", "not", "of", "on", "or", "such", "that", "the", "their", "then", "there", "these", "they",
+		"this", "to", "was", "will", "with",
+	},
+	"_none_": {},
+}
+
+// StopFilter removes stop words from the token stream. Removed tokens
+// leave a gap in token positions so that phrases remain correctly spaced
+type StopFilter struct {
+	Words      map[string]struct{}
+	IgnoreCase bool
+}
+
+// NewStopFilter creates a StopFilter removing words
+func NewStopFilter(words []string, ignoreCase bool) StopFilter {
+	f := StopFilter{Words: make(map[string]struct{}), IgnoreCase: ignoreCase}
+	var t struct{}
+	for _, w := range words {
+		if ignoreCase {
+			w = strings.ToLower(w)
+		}
+		f.Words[w] = t
+	}
+	return f
+}
+
+func (f StopFilter) Filter(tokens []Token) []Token {
+	result := tokens[:0]
+	for _, token := range tokens {
+		term := token.Term
+		if f.IgnoreCase {
+			term = strings.ToLower(term)
+		}
+		if _, ok := f.Words[term]; !ok {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// stopFilterFromConfig creates a StopFilter from the stopwords and
+// ignore_case parameters. stopwords is either the name of a built-in
+// list such as _english_ or a list of words
+func stopFilterFromConfig(cfg Config, def string) (StopFilter, error) {
+	words, err := cfg.Strings("stopwords")
+	if err != nil {
+		return StopFilter{}, err
+	}
+	if _, ok := cfg["stopwords"]; !ok {
+		words = []string{def}
+	}
+	if len(words) == 1 && strings.HasPrefix(words[0], "_") {
+		list, ok := stopWords[words[0]]
+		if !ok {
+			return StopFilter{}, fmt.Errorf("unknown stop words %s", words[0])
+		}
+		words = list
+	}
+	ignoreCase, err := cfg.Bool("ignore_case", false)
+	if err != nil {
+		return StopFilter{}, err
+	}
+	return NewStopFilter(words, ignoreCase), nil
+}
@@ -0,0 +1,80 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStopFilter_Filter(t *testing.T) {
+	tokens := StandardTokenizer{}.Tokenize("The state of the art")
+	have := NewStopFilter([]string{"the", "of"}, false).Filter(tokens)
+	want := []Token{
+		{Term: "The", Position: 0, Start: 0, End: 3, Type: TypeAlphaNum},
+		{Term: "state", Position: 1, Start: 4, End: 9, Type: TypeAlphaNum},
+		{Term: "art", Position: 4, Start: 17, End: 20, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, have)
+
+	// ignore case
+	tokens = StandardTokenizer{}.Tokenize("The state of the art")
+	have = NewStopFilter([]string{"The", "OF"}, true).Filter(tokens)
+	want = []Token{
+		{Term: "state", Position: 1, Start: 4, End: 9, Type: TypeAlphaNum},
+		{Term: "art", Position: 4, Start: 17, End: 20, Type: TypeAlphaNum},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestStopFilterFromConfig(t *testing.T) {
+	for _, tcase := range []struct {
+		name    string
+		cfg     Config
+		content string
+		want    []string
+		err     error
+	}{
+		{"default list", Config{}, "the cat", []string{"cat"}, nil},
+		{"built-in list", Config{"stopwords": "_english_"}, "the cat", []string{"cat"}, nil},
+		{"no stop words", Config{"stopwords": "_none_"}, "the cat", []string{"the", "cat"}, nil},
+		{"custom list", Config{"stopwords": "cat, dog"}, "the cat", []string{"the"}, nil},
+		{"custom array", Config{"stopwords": []interface{}{"the"}}, "the cat", []string{"cat"}, nil},
+		{"unknown list", Config{"stopwords": "_klingon_"}, "", nil, errors.New("unknown stop words _klingon_")},
+		{"invalid ignore case", Config{"ignore_case": "x"}, "", nil, errors.New("expected boolean for ignore_case")},
+		{"invalid list", Config{"stopwords": 1}, "", nil, errors.New("expected array of strings for stopwords")},
+	} {
+		cfg := Config{"type": "stop"}
+		for k, v := range tcase.cfg {
+			cfg[k] = v
+		}
+		analysis := Analysis{Filters: map[string]Config{"stop_words": cfg}}
+		a, err := analysis.NewAnalyser("custom", Config{"tokenizer": "standard", "filter": "stop_words"})
+		assert.Equal(t, tcase.err, err, tcase.name)
+		if err != nil {
+			continue
+		}
+		r, err := a.Terms(tcase.content)
+		assert.Nil(t, err)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+}
+
+func TestStandardAnalyser_StopWords(t *testing.T) {
+	a, err := NewAnalyser("standard", nil)
+	assert.Nil(t, err)
+	r, _ := a.Terms("The cat")
+	assert.Equal(t, []string{"the", "cat"}, r)
+
+	a, err = NewAnalyser("standard", Config{"stopwords": "_english_"})
+	assert.Nil(t, err)
+	r, _ = a.Terms("The cat")
+	assert.Equal(t, []string{"cat"}, r)
+
+	a, err = NewAnalyser("stop", nil)
+	assert.Nil(t, err)
+	r, _ = a.Terms("The cat")
+	assert.Equal(t, []string{"cat"}, r)
+
+	_, err = NewAnalyser("standard", Config{"stopwords": "_klingon_"})
+	assert.Equal(t, errors.New("unknown stop words _klingon_"), err)
+}
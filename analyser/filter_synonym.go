@@ -0,0 +1,210 @@
+package analyser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const TypeSynonym = "SYNONYM"
+
+// ConfigDir is the directory that files named in analysis settings, such
+// as synonyms_path, are read from. Settings may be sent by any client so
+// paths outside of it are rejected
+var ConfigDir = "config"
+
+// SynonymFilter adds or replaces tokens matching a synonym rule.
+// Rules use the Solr synonyms format, either a list of equivalent
+// phrases "laptop, notebook" or an explicit mapping
+// "nyc, big apple => new york city". A phrase may contain more than
+// one word, whose words are stacked on the positions from the start of
+// the match without moving the following tokens, so that the positions
+// of a document are the same whether or not synonyms are applied
+type SynonymFilter struct {
+	// Synonyms maps a phrase with words separated by a space to its replacements
+	Synonyms   map[string][][]string
+	IgnoreCase bool
+	// the number of words in the longest phrase
+	maxWords int
+}
+
+// NewSynonymFilter creates a SynonymFilter from rules. When expand is
+// false equivalent phrases are all replaced by the first phrase
+func NewSynonymFilter(rules []string, expand bool, ignoreCase bool) (*SynonymFilter, error) {
+	f := &SynonymFilter{Synonyms: make(map[string][][]string), IgnoreCase: ignoreCase}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+		var from, to [][]string
+		parts := strings.Split(rule, "=>")
+		switch len(parts) {
+		case 1:
+			from = f.parsePhrases(parts[0])
+			to = from
+			if !expand {
+				to = from[:1]
+			}
+		case 2:
+			from = f.parsePhrases(parts[0])
+			to = f.parsePhrases(parts[1])
+		}
+		if len(from) == 0 || len(to) == 0 {
+			return nil, fmt.Errorf("invalid synonym rule %s", rule)
+		}
+		for _, phrase := range from {
+			if len(phrase) > f.maxWords {
+				f.maxWords = len(phrase)
+			}
+			key := strings.Join(phrase, " ")
+			f.Synonyms[key] = append(f.Synonyms[key], to...)
+		}
+	}
+	return f, nil
+}
+
+// parsePhrases splits a comma separated list of phrases into words
+func (f *SynonymFilter) parsePhrases(s string) [][]string {
+	var phrases [][]string
+	for _, p := range strings.Split(s, ",") {
+		if f.IgnoreCase {
+			p = strings.ToLower(p)
+		}
+		if words := strings.Fields(p); len(words) > 0 {
+			phrases = append(phrases, words)
+		}
+	}
+	return phrases
+}
+
+func (f *SynonymFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	for i := 0; i < len(tokens); {
+		n, replacements := f.match(tokens[i:])
+		if n == 0 {
+			result = append(result, tokens[i])
+			i++
+			continue
+		}
+		start := tokens[i].Position
+		// synonyms span the text of all of the matched tokens
+		startOffset, endOffset := tokens[i].Start, tokens[i+n-1].End
+		matched := make([]string, n)
+		for j := range matched {
+			matched[j] = tokens[i+j].Term
+		}
+		for _, phrase := range replacements {
+			// a replacement equal to the matched words keeps the original tokens
+			original := strings.Join(phrase, " ") == strings.Join(matched, " ")
+			for j, word := range phrase {
+				t := Token{Term: word, Position: start + j, Start: startOffset, End: endOffset, Type: TypeSynonym}
+				if original {
+					t = tokens[i+j]
+				}
+				result = append(result, t)
+			}
+		}
+		i += n
+	}
+	// words of a longer phrase are at the positions of following tokens
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].Position < result[b].Position
+	})
+	return result
+}
+
+// match finds the longest phrase at the start of tokens with a synonym
+// returning the number of tokens matched and the replacements
+func (f *SynonymFilter) match(tokens []Token) (int, [][]string) {
+	n := f.maxWords
+	if n > len(tokens) {
+		n = len(tokens)
+	}
+	for ; n > 0; n-- {
+		words := make([]string, n)
+		sequential := true
+		for j := 0; j < n; j++ {
+			if j > 0 && tokens[j].Position != tokens[j-1].Position+1 {
+				sequential = false
+				break
+			}
+			words[j] = tokens[j].Term
+			if f.IgnoreCase {
+				words[j] = strings.ToLower(words[j])
+			}
+		}
+		if !sequential {
+			continue
+		}
+		if replacements, ok := f.Synonyms[strings.Join(words, " ")]; ok {
+			return n, replacements
+		}
+	}
+	return 0, nil
+}
+
+// synonymFilterFromConfig creates a SynonymFilter from the rules in the
+// synonyms parameter and the file named by synonyms_path relative to
+// ConfigDir. Inline rules given as a string are separated by semicolons
+// or new lines
+func synonymFilterFromConfig(cfg Config) (TokenFilter, error) {
+	var rules []string
+	switch s := cfg["synonyms"].(type) {
+	case nil:
+	case string:
+		rules = strings.FieldsFunc(s, func(r rune) bool {
+			return r == ';' || r == '\n'
+		})
+	default:
+		var err error
+		rules, err = cfg.Strings("synonyms")
+		if err != nil {
+			return nil, err
+		}
+	}
+	path, err := cfg.String("synonyms_path", "")
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		file, err := openConfigFile("synonyms_path", path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			rules = append(rules, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	expand, err := cfg.Bool("expand", true)
+	if err != nil {
+		return nil, err
+	}
+	ignoreCase, err := cfg.Bool("ignore_case", false)
+	if err != nil {
+		return nil, err
+	}
+	return NewSynonymFilter(rules, expand, ignoreCase)
+}
+
+// openConfigFile opens the file at path relative to ConfigDir given by the
+// parameter key. Errors do not reveal the location of ConfigDir
+func openConfigFile(key string, path string) (*os.File, error) {
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s %s is outside of the config directory", key, path)
+	}
+	file, err := os.Open(filepath.Join(ConfigDir, clean))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s %s", key, path)
+	}
+	return file, nil
+}
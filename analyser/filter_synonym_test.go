@@ -0,0 +1,138 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSynonymFilter_Filter(t *testing.T) {
+	f, err := NewSynonymFilter([]string{
+		"laptop, notebook",
+		"nyc, big apple => new york city",
+		"# a comment",
+		"",
+	}, true, false)
+	assert.Nil(t, err)
+
+	for _, tcase := range []struct {
+		name    string
+		content string
+		want    []Token
+	}{
+		{
+			"equivalent synonyms at the same position",
+			"a notebook",
+			[]Token{
+				{Term: "a", Position: 0, Start: 0, End: 1, Type: TypeWord},
+				{Term: "laptop", Position: 1, Start: 2, End: 10, Type: TypeSynonym},
+				{Term: "notebook", Position: 1, Start: 2, End: 10, Type: TypeWord},
+			},
+		},
+		{
+			"multi word replacement is stacked on following tokens",
+			"nyc at night",
+			[]Token{
+				{Term: "new", Position: 0, Start: 0, End: 3, Type: TypeSynonym},
+				{Term: "york", Position: 1, Start: 0, End: 3, Type: TypeSynonym},
+				{Term: "at", Position: 1, Start: 4, End: 6, Type: TypeWord},
+				{Term: "city", Position: 2, Start: 0, End: 3, Type: TypeSynonym},
+				{Term: "night", Position: 2, Start: 7, End: 12, Type: TypeWord},
+			},
+		},
+		{
+			"multi word match is replaced",
+			"the big apple",
+			[]Token{
+				{Term: "the", Position: 0, Start: 0, End: 3, Type: TypeWord},
+				{Term: "new", Position: 1, Start: 4, End: 13, Type: TypeSynonym},
+				{Term: "york", Position: 2, Start: 4, End: 13, Type: TypeSynonym},
+				{Term: "city", Position: 3, Start: 4, End: 13, Type: TypeSynonym},
+			},
+		},
+		{
+			"no synonyms",
+			"big day",
+			[]Token{
+				{Term: "big", Position: 0, Start: 0, End: 3, Type: TypeWord},
+				{Term: "day", Position: 1, Start: 4, End: 7, Type: TypeWord},
+			},
+		},
+	} {
+		have := f.Filter(WhitespaceTokenizer{}.Tokenize(tcase.content))
+		assert.Equal(t, tcase.want, have, tcase.name)
+	}
+
+	// phrases must be in sequence
+	have := f.Filter([]Token{{Term: "big", Position: 0}, {Term: "apple", Position: 2}})
+	assert.Equal(t, []Token{{Term: "big", Position: 0}, {Term: "apple", Position: 2}}, have)
+
+	// contract equivalent synonyms
+	f, err = NewSynonymFilter([]string{"laptop, notebook"}, false, false)
+	assert.Nil(t, err)
+	have = f.Filter([]Token{{Term: "notebook", Position: 0}})
+	assert.Equal(t, []Token{{Term: "laptop", Position: 0, Type: TypeSynonym}}, have)
+
+	// ignore case
+	f, err = NewSynonymFilter([]string{"NYC => New York"}, true, true)
+	assert.Nil(t, err)
+	have = f.Filter([]Token{{Term: "nyc", Position: 0}})
+	assert.Equal(t, []Token{{Term: "new", Position: 0, Type: TypeSynonym}, {Term: "york", Position: 1, Type: TypeSynonym}}, have)
+
+	// invalid rules
+	_, err = NewSynonymFilter([]string{"a =>"}, true, false)
+	assert.Equal(t, errors.New("invalid synonym rule a =>"), err)
+	_, err = NewSynonymFilter([]string{"a => b => c"}, true, false)
+	assert.Equal(t, errors.New("invalid synonym rule a => b => c"), err)
+}
+
+func TestSynonymFilterFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	defer func(configDir string) { ConfigDir = configDir }(ConfigDir)
+	ConfigDir = filepath.Join(dir, "config")
+	assert.Nil(t, os.MkdirAll(filepath.Join(ConfigDir, "analysis"), 0700))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(ConfigDir, "analysis", "synonyms.txt"), []byte("# synonyms\nsofa, couch\n"), 0600))
+	// a file outside of the config directory
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("a, b\n"), 0600))
+
+	for _, tcase := range []struct {
+		name    string
+		cfg     Config
+		content string
+		want    []string
+		err     error
+	}{
+		{"inline string", Config{"synonyms": "tv, television; nyc => new york"}, "tv nyc", []string{"tv", "television", "new", "york"}, nil},
+		{"inline array", Config{"synonyms": []interface{}{"tv, television"}}, "tv", []string{"tv", "television"}, nil},
+		{"file", Config{"synonyms_path": "analysis/synonyms.txt"}, "couch", []string{"sofa", "couch"}, nil},
+		{"file cleaned", Config{"synonyms_path": "analysis/../analysis/synonyms.txt"}, "couch", []string{"sofa", "couch"}, nil},
+		{"file not found", Config{"synonyms_path": "analysis/x.txt"}, "", nil, errors.New("failed to read synonyms_path analysis/x.txt")},
+		{"absolute path", Config{"synonyms_path": filepath.Join(dir, "secret.txt")}, "", nil, errors.New("synonyms_path " + filepath.Join(dir, "secret.txt") + " is outside of the config directory")},
+		{"parent path", Config{"synonyms_path": "../secret.txt"}, "", nil, errors.New("synonyms_path ../secret.txt is outside of the config directory")},
+		{"parent path within", Config{"synonyms_path": "analysis/../../secret.txt"}, "", nil, errors.New("synonyms_path analysis/../../secret.txt is outside of the config directory")},
+		{"invalid path", Config{"synonyms_path": 1}, "", nil, errors.New("expected string for synonyms_path")},
+		{"invalid synonyms", Config{"synonyms": 1}, "", nil, errors.New("expected array of strings for synonyms")},
+		{"invalid expand", Config{"expand": "x"}, "", nil, errors.New("expected boolean for expand")},
+		{"invalid ignore case", Config{"ignore_case": "x"}, "", nil, errors.New("expected boolean for ignore_case")},
+	} {
+		cfg := Config{"type": "synonym"}
+		for k, v := range tcase.cfg {
+			cfg[k] = v
+		}
+		analysis := Analysis{Filters: map[string]Config{"synonyms": cfg}}
+		a, err := analysis.NewAnalyser("custom", Config{"tokenizer": "standard", "filter": "lowercase,synonyms"})
+		if tcase.err != nil {
+			assert.EqualError(t, err, tcase.err.Error(), tcase.name)
+			continue
+		}
+		assert.Nil(t, err, tcase.name)
+		r, err := a.Terms(tcase.content)
+		assert.Nil(t, err)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+}
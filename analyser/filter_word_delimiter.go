@@ -0,0 +1,193 @@
+package analyser
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordDelimiterFilter splits tokens into sub words on characters that are
+// not letters or digits, changes of case and transitions between letters
+// and digits, so that "PowerShot-500", "power_shot" and "a.b@example.com"
+// are split into their parts. Parts take consecutive positions from the
+// position of the token, moving following tokens to later positions
+type WordDelimiterFilter struct {
+	GenerateWordParts     bool
+	GenerateNumberParts   bool
+	CatenateWords         bool
+	CatenateNumbers       bool
+	CatenateAll           bool
+	SplitOnCaseChange     bool
+	SplitOnNumerics       bool
+	StemEnglishPossessive bool
+	// PreserveOriginal also emits the original token
+	PreserveOriginal bool
+}
+
+// NewWordDelimiterFilter creates a WordDelimiterFilter generating word
+// and number parts split on case changes and numerics
+func NewWordDelimiterFilter() WordDelimiterFilter {
+	return WordDelimiterFilter{
+		GenerateWordParts:     true,
+		GenerateNumberParts:   true,
+		SplitOnCaseChange:     true,
+		SplitOnNumerics:       true,
+		StemEnglishPossessive: true,
+	}
+}
+
+// wordDelimiterFilterFromConfig creates a WordDelimiterFilter from the
+// parameters named after each option
+func wordDelimiterFilterFromConfig(cfg Config) (TokenFilter, error) {
+	f := NewWordDelimiterFilter()
+	for key, option := range map[string]*bool{
+		"generate_word_parts":     &f.GenerateWordParts,
+		"generate_number_parts":   &f.GenerateNumberParts,
+		"catenate_words":          &f.CatenateWords,
+		"catenate_numbers":        &f.CatenateNumbers,
+		"catenate_all":            &f.CatenateAll,
+		"split_on_case_change":    &f.SplitOnCaseChange,
+		"split_on_numerics":       &f.SplitOnNumerics,
+		"stem_english_possessive": &f.StemEnglishPossessive,
+		"preserve_original":       &f.PreserveOriginal,
+	} {
+		v, err := cfg.Bool(key, *option)
+		if err != nil {
+			return nil, err
+		}
+		*option = v
+	}
+	return f, nil
+}
+
+// subWord is a part of a term between byte offsets start and end
+type subWord struct {
+	start  int
+	end    int
+	number bool
+}
+
+func (f WordDelimiterFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	// how far following tokens are moved by the parts of earlier tokens
+	shift := 0
+	for _, token := range tokens {
+		token.Position += shift
+		parts := f.split(token.Term)
+		if len(parts) == 1 && parts[0].start == 0 && parts[0].end == len(token.Term) {
+			result = append(result, token)
+			continue
+		}
+		// offsets of parts are only known when the token is the original text
+		exact := token.End-token.Start == len(token.Term)
+		part := func(term string, start int, end int, pos int) Token {
+			t := Token{Term: term, Position: pos, Start: token.Start, End: token.End, Type: token.Type, Keyword: token.Keyword}
+			if exact {
+				t.Start, t.End = token.Start+start, token.Start+end
+			}
+			return t
+		}
+
+		var emitted []Token
+		if f.PreserveOriginal {
+			emitted = append(emitted, token)
+		}
+		for i, p := range parts {
+			if (p.number && f.GenerateNumberParts) || (!p.number && f.GenerateWordParts) {
+				emitted = append(emitted, part(token.Term[p.start:p.end], p.start, p.end, token.Position+i))
+			}
+		}
+		// catenate runs of word parts and number parts
+		for i := 0; i < len(parts); {
+			j := i + 1
+			for j < len(parts) && parts[j].number == parts[i].number {
+				j++
+			}
+			if j-i > 1 && ((parts[i].number && f.CatenateNumbers) || (!parts[i].number && f.CatenateWords)) {
+				emitted = append(emitted, part(catenate(token.Term, parts[i:j]), parts[i].start, parts[j-1].end, token.Position+i))
+			}
+			i = j
+		}
+		if f.CatenateAll && len(parts) > 1 {
+			emitted = append(emitted, part(catenate(token.Term, parts), parts[0].start, parts[len(parts)-1].end, token.Position))
+		}
+		sort.SliceStable(emitted, func(a, b int) bool {
+			return emitted[a].Position < emitted[b].Position
+		})
+		result = append(result, emitted...)
+		if len(parts) > 1 {
+			shift += len(parts) - 1
+		}
+	}
+	return result
+}
+
+func catenate(term string, parts []subWord) string {
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(term[p.start:p.end])
+	}
+	return b.String()
+}
+
+// split finds the sub words of term
+func (f WordDelimiterFilter) split(term string) []subWord {
+	var parts []subWord
+	start := -1
+	var prev rune
+	for i := 0; i <= len(term); {
+		r, size := utf8.DecodeRuneInString(term[i:])
+		if i == len(term) || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if start >= 0 {
+				parts = append(parts, subWord{start: start, end: i, number: unicode.IsDigit(prev)})
+				start = -1
+			}
+			if i == len(term) {
+				break
+			}
+			i += size
+			continue
+		}
+		if start >= 0 && f.isBoundary(prev, r, term[i+size:]) {
+			parts = append(parts, subWord{start: start, end: i, number: unicode.IsDigit(prev)})
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+		prev = r
+		i += size
+	}
+	if f.StemEnglishPossessive {
+		// remove 's from the end of words
+		for i := 0; i+1 < len(parts); i++ {
+			p, s := parts[i], parts[i+1]
+			if s.end-s.start == 1 && (term[s.start] == 's' || term[s.start] == 'S') &&
+				s.start-p.end == 1 && term[p.end] == '\'' && !p.number {
+				parts = append(parts[:i+1], parts[i+2:]...)
+			}
+		}
+	}
+	return parts
+}
+
+// isBoundary reports whether there is a sub word boundary between
+// r and the rune before it, followed by rest
+func (f WordDelimiterFilter) isBoundary(prev rune, r rune, rest string) bool {
+	if f.SplitOnNumerics && unicode.IsDigit(prev) != unicode.IsDigit(r) {
+		return true
+	}
+	if !f.SplitOnCaseChange || !unicode.IsLetter(prev) || !unicode.IsLetter(r) {
+		return false
+	}
+	if unicode.IsLower(prev) && unicode.IsUpper(r) {
+		return true
+	}
+	// the last upper case letter of an acronym starts the next word, "XMLParser"
+	if unicode.IsUpper(prev) && unicode.IsUpper(r) {
+		next, _ := utf8.DecodeRuneInString(rest)
+		return unicode.IsLower(next)
+	}
+	return false
+}
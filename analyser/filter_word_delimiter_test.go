@@ -0,0 +1,80 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWordDelimiterFilter_Filter(t *testing.T) {
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	defaults := NewWordDelimiterFilter()
+	catenate := NewWordDelimiterFilter()
+	catenate.CatenateWords, catenate.CatenateNumbers, catenate.CatenateAll = true, true, true
+	noCase := NewWordDelimiterFilter()
+	noCase.SplitOnCaseChange, noCase.SplitOnNumerics = false, false
+	preserve := NewWordDelimiterFilter()
+	preserve.PreserveOriginal = true
+	numbers := NewWordDelimiterFilter()
+	numbers.GenerateWordParts = false
+
+	for _, tcase := range []struct {
+		name    string
+		filter  WordDelimiterFilter
+		content string
+		want    []string
+	}{
+		{"camel case", defaults, "parseHTTPRequest", []string{"parse", "HTTP", "Request"}},
+		{"snake case", defaults, "max_tokens_per_field", []string{"max", "tokens", "per", "field"}},
+		{"numerics", defaults, "SD500 x86", []string{"SD", "500", "x", "86"}},
+		{"email", defaults, "john.smith@example.com", []string{"john", "smith", "example", "com"}},
+		{"possessive", defaults, "O'Neil's", []string{"O", "Neil"}},
+		{"unchanged", defaults, "word", []string{"word"}},
+		{"only delimiters", defaults, "-- x", []string{"x"}},
+		{"no case or numeric split", noCase, "wiFi-SD500", []string{"wiFi", "SD500"}},
+		{"catenate", catenate, "wi-fi-4000-12", []string{"wi", "wifi", "wifi400012", "fi", "4000", "400012", "12"}},
+		{"preserve original", preserve, "PowerShot", []string{"PowerShot", "Power", "Shot"}},
+		{"number parts only", numbers, "abc-123", []string{"123"}},
+	} {
+		have := tcase.filter.Filter(WhitespaceTokenizer{}.Tokenize(tcase.content))
+		assert.Equal(t, tcase.want, terms(have), tcase.name)
+	}
+
+	// parts take consecutive positions and offsets within the token
+	have := defaults.Filter(WhitespaceTokenizer{}.Tokenize("a fooBar baz"))
+	want := []Token{
+		{Term: "a", Position: 0, Start: 0, End: 1, Type: TypeWord},
+		{Term: "foo", Position: 1, Start: 2, End: 5, Type: TypeWord},
+		{Term: "Bar", Position: 2, Start: 5, End: 8, Type: TypeWord},
+		{Term: "baz", Position: 3, Start: 9, End: 12, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	// offsets are those of the token when its term has been changed
+	have = defaults.Filter([]Token{{Term: "foo-bar", Position: 0, Start: 0, End: 3}})
+	want = []Token{
+		{Term: "foo", Position: 0, Start: 0, End: 3},
+		{Term: "bar", Position: 1, Start: 0, End: 3},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestWordDelimiterFilterFromConfig(t *testing.T) {
+	f, err := wordDelimiterFilterFromConfig(Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, NewWordDelimiterFilter(), f)
+
+	f, err = wordDelimiterFilterFromConfig(Config{"preserve_original": "true", "split_on_case_change": false})
+	assert.Nil(t, err)
+	assert.True(t, f.(WordDelimiterFilter).PreserveOriginal)
+	assert.False(t, f.(WordDelimiterFilter).SplitOnCaseChange)
+
+	_, err = wordDelimiterFilterFromConfig(Config{"catenate_all": "x"})
+	assert.Equal(t, errors.New("expected boolean for catenate_all"), err)
+}
@@ -0,0 +1,208 @@
+package analyser
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// character classes that may be kept in n-gram tokens
+var tokenChars = map[string]func(r rune) bool{
+	"letter":      unicode.IsLetter,
+	"digit":       unicode.IsDigit,
+	"whitespace":  unicode.IsSpace,
+	"punctuation": unicode.IsPunct,
+	"symbol":      unicode.IsSymbol,
+}
+
+// DefaultMaxNGramDiff is the largest difference between max_gram and
+// min_gram of the ngram tokenizer and filter unless max_ngram_diff is set,
+// as the number of n-grams of each word grows with the difference
+const DefaultMaxNGramDiff = 1
+
+// NGramTokenizer splits text into words made of TokenChars and
+// emits the n-grams of each word between MinGram and MaxGram
+// characters long
+type NGramTokenizer struct {
+	MinGram int
+	MaxGram int
+	// Edge emits only n-grams anchored to the start of each word
+	Edge bool
+	// TokenChars are the character classes kept in words, any
+	// character is kept when empty
+	TokenChars []string
+}
+
+// NewNGramTokenizer creates an NGramTokenizer validating its configuration
+func NewNGramTokenizer(minGram int, maxGram int, edge bool, chars []string) (NGramTokenizer, error) {
+	if minGram < 1 || maxGram < minGram {
+		return NGramTokenizer{}, errors.New("invalid min_gram or max_gram")
+	}
+	for _, c := range chars {
+		if _, ok := tokenChars[c]; !ok {
+			return NGramTokenizer{}, fmt.Errorf("unknown token_chars %s", c)
+		}
+	}
+	return NGramTokenizer{MinGram: minGram, MaxGram: maxGram, Edge: edge, TokenChars: chars}, nil
+}
+
+// nGramTokenizerFromConfig creates an NGramTokenizer from the
+// min_gram, max_gram, max_ngram_diff and token_chars parameters
+func nGramTokenizerFromConfig(cfg Config, edge bool) (Tokenizer, error) {
+	minGram, err := cfg.Int("min_gram", 1)
+	if err != nil {
+		return nil, err
+	}
+	maxGram, err := cfg.Int("max_gram", 2)
+	if err != nil {
+		return nil, err
+	}
+	chars, err := cfg.Strings("token_chars")
+	if err != nil {
+		return nil, err
+	}
+	t, err := NewNGramTokenizer(minGram, maxGram, edge, chars)
+	if err != nil {
+		return nil, err
+	}
+	return t, checkNGramDiff(cfg, minGram, maxGram, edge)
+}
+
+// checkNGramDiff returns an error when the difference between maxGram and
+// minGram is larger than the max_ngram_diff parameter. Edge n-grams are
+// not limited as each word has at most one of each length
+func checkNGramDiff(cfg Config, minGram int, maxGram int, edge bool) error {
+	if edge {
+		return nil
+	}
+	maxDiff, err := cfg.Int("max_ngram_diff", DefaultMaxNGramDiff)
+	if err != nil {
+		return err
+	}
+	if maxGram-minGram > maxDiff {
+		return fmt.Errorf("difference between max_gram and min_gram must be at most max_ngram_diff %d", maxDiff)
+	}
+	return nil
+}
+
+func (t NGramTokenizer) Tokenize(content string) []Token {
+	var tokens []Token
+	var word []rune
+	// the byte offset of each rune in word followed by the end of word
+	var offsets []int
+	emit := func(end int) {
+		offsets = append(offsets, end)
+		for _, g := range nGrams(word, t.MinGram, t.MaxGram, t.Edge) {
+			tokens = append(tokens, Token{
+				Term:     string(word[g.start:g.end]),
+				Position: len(tokens),
+				Start:    offsets[g.start],
+				End:      offsets[g.end],
+				Type:     TypeWord,
+			})
+		}
+		word, offsets = word[:0], offsets[:0]
+	}
+	for i, r := range content {
+		if t.isTokenChar(r) {
+			word = append(word, r)
+			offsets = append(offsets, i)
+		} else {
+			emit(i)
+		}
+	}
+	emit(len(content))
+	return tokens
+}
+
+func (t NGramTokenizer) isTokenChar(r rune) bool {
+	if len(t.TokenChars) == 0 {
+		return true
+	}
+	for _, c := range t.TokenChars {
+		if tokenChars[c](r) {
+			return true
+		}
+	}
+	return false
+}
+
+// nGram is the range of characters in a word forming an n-gram
+type nGram struct {
+	start int
+	end   int
+}
+
+// nGrams returns the n-grams of word between min and max characters long
+// ordered by start then length. When edge is set only n-grams starting at
+// the beginning of word are returned
+func nGrams(word []rune, min int, max int, edge bool) []nGram {
+	var grams []nGram
+	for start := 0; start < len(word); start++ {
+		for n := min; n <= max && start+n <= len(word); n++ {
+			grams = append(grams, nGram{start: start, end: start + n})
+		}
+		if edge {
+			break
+		}
+	}
+	return grams
+}
+
+// NGramFilter replaces each token with its n-grams between MinGram
+// and MaxGram characters long, at the position of the original token
+type NGramFilter struct {
+	MinGram int
+	MaxGram int
+	// Edge emits only n-grams anchored to the start of each token
+	Edge bool
+	// PreserveOriginal also emits the original token
+	PreserveOriginal bool
+}
+
+// NewNGramFilter creates an NGramFilter validating its configuration
+func NewNGramFilter(minGram int, maxGram int, edge bool, preserveOriginal bool) (NGramFilter, error) {
+	if minGram < 1 || maxGram < minGram {
+		return NGramFilter{}, errors.New("invalid min_gram or max_gram")
+	}
+	return NGramFilter{MinGram: minGram, MaxGram: maxGram, Edge: edge, PreserveOriginal: preserveOriginal}, nil
+}
+
+// nGramFilterFromConfig creates an NGramFilter from the
+// min_gram, max_gram, max_ngram_diff and preserve_original parameters
+func nGramFilterFromConfig(cfg Config, edge bool) (TokenFilter, error) {
+	minGram, err := cfg.Int("min_gram", 1)
+	if err != nil {
+		return nil, err
+	}
+	maxGram, err := cfg.Int("max_gram", 2)
+	if err != nil {
+		return nil, err
+	}
+	preserve, err := cfg.Bool("preserve_original", false)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewNGramFilter(minGram, maxGram, edge, preserve)
+	if err != nil {
+		return nil, err
+	}
+	return f, checkNGramDiff(cfg, minGram, maxGram, edge)
+}
+
+func (f NGramFilter) Filter(tokens []Token) []Token {
+	var result []Token
+	for _, token := range tokens {
+		word := []rune(token.Term)
+		grams := nGrams(word, f.MinGram, f.MaxGram, f.Edge)
+		for _, g := range grams {
+			t := token
+			t.Term = string(word[g.start:g.end])
+			result = append(result, t)
+		}
+		if f.PreserveOriginal && (len(word) < f.MinGram || len(word) > f.MaxGram) {
+			result = append(result, token)
+		}
+	}
+	return result
+}
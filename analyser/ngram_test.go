@@ -0,0 +1,106 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNGramTokenizer_Tokenize(t *testing.T) {
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	for _, tcase := range []struct {
+		name      string
+		tokenizer NGramTokenizer
+		content   string
+		want      []string
+	}{
+		{"ngram", NGramTokenizer{MinGram: 1, MaxGram: 2}, "abc", []string{"a", "ab", "b", "bc", "c"}},
+		{"ngram all characters", NGramTokenizer{MinGram: 2, MaxGram: 2}, "a b", []string{"a ", " b"}},
+		{"ngram token chars", NGramTokenizer{MinGram: 2, MaxGram: 3, TokenChars: []string{"letter"}}, "ab, cd", []string{"ab", "cd"}},
+		{"edge ngram", NGramTokenizer{MinGram: 2, MaxGram: 4, Edge: true, TokenChars: []string{"letter", "digit"}}, "Laptop x1", []string{"La", "Lap", "Lapt", "x1"}},
+		{"multi byte characters", NGramTokenizer{MinGram: 1, MaxGram: 1}, "日本", []string{"日", "本"}},
+	} {
+		assert.Equal(t, tcase.want, terms(tcase.tokenizer.Tokenize(tcase.content)), tcase.name)
+	}
+
+	// positions increment for each gram
+	have := NGramTokenizer{MinGram: 1, MaxGram: 2, Edge: true}.Tokenize("ab")
+	assert.Equal(t, []Token{{Term: "a", Position: 0, Start: 0, End: 1, Type: TypeWord}, {Term: "ab", Position: 1, Start: 0, End: 2, Type: TypeWord}}, have)
+
+	// offsets of grams within multi byte words
+	have = NGramTokenizer{MinGram: 2, MaxGram: 2, TokenChars: []string{"letter"}}.Tokenize("é日本")
+	want := []Token{
+		{Term: "é日", Position: 0, Start: 0, End: 5, Type: TypeWord},
+		{Term: "日本", Position: 1, Start: 2, End: 8, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestNGramFilter_Filter(t *testing.T) {
+	tokens := []Token{{Term: "abc", Position: 0}, {Term: "d", Position: 1}}
+	have := NGramFilter{MinGram: 2, MaxGram: 2}.Filter(tokens)
+	assert.Equal(t, []Token{{Term: "ab", Position: 0}, {Term: "bc", Position: 0}}, have)
+
+	have = NGramFilter{MinGram: 1, MaxGram: 2, Edge: true, PreserveOriginal: true}.Filter(tokens)
+	want := []Token{
+		{Term: "a", Position: 0},
+		{Term: "ab", Position: 0},
+		{Term: "abc", Position: 0},
+		{Term: "d", Position: 1},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestNewAnalyser_NGram(t *testing.T) {
+	tokenizer := func(cfg Config) Analysis {
+		return Analysis{Tokenizers: map[string]Config{"grams": cfg}}
+	}
+	filter := func(cfg Config) Analysis {
+		return Analysis{Filters: map[string]Config{"grams": cfg}}
+	}
+
+	a, err := tokenizer(Config{"type": "edge_ngram", "min_gram": "2", "max_gram": 3.0, "token_chars": "letter"}).NewAnalyser("custom", Config{"tokenizer": "grams"})
+	assert.Nil(t, err)
+	assert.Equal(t, &FullTextAnalyser{Tokenizer: NGramTokenizer{MinGram: 2, MaxGram: 3, Edge: true, TokenChars: []string{"letter"}}}, a)
+
+	a, err = filter(Config{"type": "edge_ngram", "max_gram": 3}).NewAnalyser("custom", Config{"tokenizer": "standard", "filter": "lowercase,grams"})
+	assert.Nil(t, err)
+	r, err := a.Terms("Dell")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"d", "de", "del"}, r)
+
+	// a wider range of n-grams is allowed by max_ngram_diff
+	a, err = tokenizer(Config{"type": "ngram", "min_gram": 1, "max_gram": 3, "max_ngram_diff": 2}).NewAnalyser("custom", Config{"tokenizer": "grams"})
+	assert.Nil(t, err)
+	r, err = a.Terms("abc")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "ab", "abc", "b", "bc", "c"}, r)
+
+	for _, tcase := range []struct {
+		analysis Analysis
+		err      error
+	}{
+		{tokenizer(Config{"type": "ngram", "min_gram": 0}), errors.New("invalid min_gram or max_gram")},
+		{tokenizer(Config{"type": "ngram", "min_gram": 3}), errors.New("invalid min_gram or max_gram")},
+		{tokenizer(Config{"type": "ngram", "min_gram": "x"}), errors.New("expected integer for min_gram")},
+		{tokenizer(Config{"type": "ngram", "max_gram": 1.5}), errors.New("expected integer for max_gram")},
+		{tokenizer(Config{"type": "ngram", "min_gram": 1, "max_gram": 3}), errors.New("difference between max_gram and min_gram must be at most max_ngram_diff 1")},
+		{tokenizer(Config{"type": "ngram", "min_gram": 1, "max_gram": 100, "max_ngram_diff": 10}), errors.New("difference between max_gram and min_gram must be at most max_ngram_diff 10")},
+		{tokenizer(Config{"type": "ngram", "max_ngram_diff": "x"}), errors.New("expected integer for max_ngram_diff")},
+		{filter(Config{"type": "ngram", "min_gram": 2, "max_gram": 1000}), errors.New("difference between max_gram and min_gram must be at most max_ngram_diff 1")},
+		{tokenizer(Config{"type": "ngram", "token_chars": "emoji"}), errors.New("unknown token_chars emoji")},
+		{tokenizer(Config{"type": "ngram", "token_chars": 1}), errors.New("expected array of strings for token_chars")},
+		{filter(Config{"type": "ngram", "max_gram": 0}), errors.New("invalid min_gram or max_gram")},
+		{filter(Config{"type": "ngram", "min_gram": false}), errors.New("expected integer for min_gram")},
+		{filter(Config{"type": "ngram", "max_gram": false}), errors.New("expected integer for max_gram")},
+		{filter(Config{"type": "ngram", "preserve_original": 1}), errors.New("expected boolean for preserve_original")},
+	} {
+		assert.Equal(t, tcase.err, tcase.analysis.Validate())
+	}
+}
@@ -0,0 +1,153 @@
+package analyser
+
+import (
+	"sort"
+	"strings"
+)
+
+// OffsetMap maps byte offsets in text produced by a CharFilter back to
+// offsets in the content it was produced from. The zero value maps each
+// offset to itself
+type OffsetMap struct {
+	// segments of the filtered text in order, each starting where the
+	// difference between filtered and original offsets changes, so that
+	// text kept unchanged does not add to the size of the map
+	segments []offsetSegment
+	// length of the filtered text
+	length int
+}
+
+// offsetSegment is filtered text from Offset, either text kept from Start in
+// the original content or text replacing the original content from Start
+// to End
+type offsetSegment struct {
+	Offset   int
+	Start    int
+	End      int
+	Replaced bool
+}
+
+// segment returns the segment containing the byte at offset
+func (m OffsetMap) segment(offset int) offsetSegment {
+	i := sort.Search(len(m.segments), func(i int) bool {
+		return m.segments[i].Offset > offset
+	})
+	return m.segments[i-1]
+}
+
+// Start returns the original offset of a token starting at offset
+func (m OffsetMap) Start(offset int) int {
+	if m.segments == nil {
+		return offset
+	}
+	s := m.segment(offset)
+	if s.Replaced {
+		return s.Start
+	}
+	return s.Start + offset - s.Offset
+}
+
+// End returns the original offset of a token ending at offset
+func (m OffsetMap) End(offset int) int {
+	if m.segments == nil || offset == 0 {
+		return m.Start(offset)
+	}
+	s := m.segment(offset - 1)
+	if s.Replaced {
+		return s.End
+	}
+	return s.Start + offset - s.Offset
+}
+
+// Then returns the OffsetMap of text filtered with m and then with n,
+// mapping offsets in the final text to the original content
+func (m OffsetMap) Then(n OffsetMap) OffsetMap {
+	if m.segments == nil {
+		return n
+	}
+	if n.segments == nil {
+		return m
+	}
+	b := &offsetBuilder{}
+	for i, s := range n.segments {
+		end := n.length
+		if i+1 < len(n.segments) {
+			end = n.segments[i+1].Offset
+		}
+		if s.Replaced {
+			start := m.Start(s.Start)
+			if s.End == s.Start {
+				// inserted text has no width in the original content
+				b.add(offsetSegment{Offset: s.Offset, Start: start, End: start, Replaced: true})
+				continue
+			}
+			b.add(offsetSegment{Offset: s.Offset, Start: start, End: m.End(s.End), Replaced: true})
+			continue
+		}
+		// kept text maps through each of the segments of m it spans
+		for offset := s.Offset; offset < end || offset == s.Offset; {
+			o := s.Start + offset - s.Offset
+			ms := m.segment(o)
+			if ms.Replaced {
+				b.add(offsetSegment{Offset: offset, Start: ms.Start, End: ms.End, Replaced: true})
+			} else {
+				b.add(offsetSegment{Offset: offset, Start: ms.Start + o - ms.Offset})
+			}
+			next := m.length
+			if j := sort.Search(len(m.segments), func(j int) bool { return m.segments[j].Offset > o }); j < len(m.segments) {
+				next = m.segments[j].Offset
+			}
+			if next <= o {
+				break
+			}
+			offset += next - o
+		}
+	}
+	return OffsetMap{segments: b.segments, length: n.length}
+}
+
+// offsetBuilder builds the text produced by a CharFilter along with its OffsetMap
+type offsetBuilder struct {
+	text     strings.Builder
+	segments []offsetSegment
+}
+
+// add appends a segment unless it continues the last segment
+func (b *offsetBuilder) add(s offsetSegment) {
+	if n := len(b.segments); n > 0 {
+		last := b.segments[n-1]
+		if !s.Replaced && !last.Replaced && s.Start-last.Start == s.Offset-last.Offset {
+			return
+		}
+		if last.Offset == s.Offset {
+			// the last segment is empty
+			b.segments[n-1] = s
+			return
+		}
+	}
+	b.segments = append(b.segments, s)
+}
+
+// keep appends s found at offset in the original content
+func (b *offsetBuilder) keep(s string, offset int) {
+	if len(s) == 0 {
+		return
+	}
+	b.add(offsetSegment{Offset: b.text.Len(), Start: offset})
+	b.text.WriteString(s)
+}
+
+// replace appends s in place of the original content between start and end
+func (b *offsetBuilder) replace(s string, start int, end int) {
+	if len(s) == 0 {
+		return
+	}
+	b.add(offsetSegment{Offset: b.text.Len(), Start: start, End: end, Replaced: true})
+	b.text.WriteString(s)
+}
+
+// build returns the text and OffsetMap for original content of length n
+func (b *offsetBuilder) build(n int) (string, OffsetMap) {
+	b.add(offsetSegment{Offset: b.text.Len(), Start: n})
+	return b.text.String(), OffsetMap{segments: b.segments, length: b.text.Len()}
+}
@@ -0,0 +1,71 @@
+package analyser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestOffsetMap(t *testing.T) {
+	// identity
+	m := OffsetMap{}
+	assert.Equal(t, 3, m.Start(3))
+	assert.Equal(t, 3, m.End(3))
+
+	// "a&amp;b" filtered to "a&b"
+	b := &offsetBuilder{}
+	b.keep("a", 0)
+	b.replace("&", 1, 6)
+	b.keep("b", 6)
+	text, m := b.build(7)
+	assert.Equal(t, "a&b", text)
+	assert.Equal(t, []int{0, 1, 6, 7}, []int{m.Start(0), m.Start(1), m.Start(2), m.Start(3)})
+	assert.Equal(t, []int{0, 1, 6, 7}, []int{m.End(0), m.End(1), m.End(2), m.End(3)})
+
+	// "a&b" filtered to "a and b"
+	b = &offsetBuilder{}
+	b.keep("a", 0)
+	b.replace(" and ", 1, 2)
+	b.keep("b", 2)
+	_, n := b.build(3)
+
+	c := m.Then(n)
+	// "and" maps to "&amp;"
+	assert.Equal(t, 1, c.Start(2))
+	assert.Equal(t, 6, c.End(5))
+	// "b" maps to "b"
+	assert.Equal(t, 6, c.Start(6))
+	assert.Equal(t, 7, c.End(7))
+
+	assert.Equal(t, m, m.Then(OffsetMap{}))
+	assert.Equal(t, n, OffsetMap{}.Then(n))
+
+	// inserted text
+	b = &offsetBuilder{}
+	b.keep("a", 0)
+	b.replace("-", 1, 1)
+	b.keep("b", 1)
+	_, n = b.build(2)
+	c = m.Then(n)
+	assert.Equal(t, 1, c.Start(1))
+	assert.Equal(t, 1, c.End(2))
+
+	// kept text does not add to the size of the map
+	b = &offsetBuilder{}
+	b.keep(strings.Repeat("a", 1000), 0)
+	b.replace("&", 1000, 1005)
+	b.keep(strings.Repeat("b", 1000), 1005)
+	_, m = b.build(2005)
+	assert.Equal(t, 3, len(m.segments))
+	assert.Equal(t, []int{999, 1000, 1005, 2005}, []int{m.Start(999), m.Start(1000), m.Start(1001), m.Start(2001)})
+	assert.Equal(t, []int{1000, 1005, 2005}, []int{m.End(1000), m.End(1001), m.End(2001)})
+
+	// kept text spanning the segments of a previous map
+	b = &offsetBuilder{}
+	b.replace("x", 999, 999)
+	b.keep("a&b", 999)
+	_, n = b.build(2001)
+	c = m.Then(n)
+	assert.Equal(t, []int{999, 999, 1000, 1005, 2005}, []int{c.Start(0), c.Start(1), c.Start(2), c.Start(3), c.Start(4)})
+	assert.Equal(t, []int{999, 1000, 1005, 1006}, []int{c.End(1), c.End(2), c.End(3), c.End(4)})
+}
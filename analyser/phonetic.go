@@ -0,0 +1,579 @@
+package analyser
+
+import (
+	"strings"
+)
+
+// soundex codes of the letters A to Z, 0 for letters not coded
+const soundexCodes = "01230120022455012623010202"
+
+// Soundex encodes word as its American Soundex code, the first letter
+// followed by three digits for the consonants that follow, so that
+// Robert and Rupert are both R163. Characters other than the letters
+// A to Z are ignored. The code is empty when word has no such letters
+func Soundex(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for _, r := range strings.ToUpper(word) {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		digit := soundexCodes[r-'A']
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = digit
+			continue
+		}
+		// H and W do not separate letters with the same code
+		if r == 'H' || r == 'W' {
+			continue
+		}
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+			if len(code) == 4 {
+				break
+			}
+		}
+		last = digit
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// DoubleMetaphone encodes word using the Double Metaphone algorithm by
+// Lawrence Philips, returning a primary and an alternate code of up to
+// maxLen characters. Both codes account for the pronunciation of words
+// of English and several other origins, with the alternate code
+// differing from the primary where a word may be pronounced either way
+func DoubleMetaphone(word string, maxLen int) (string, string) {
+	value := []rune(strings.ToUpper(strings.TrimSpace(word)))
+	if len(value) == 0 {
+		return "", ""
+	}
+	m := &doubleMetaphone{value: value, maxLen: maxLen}
+	s := string(value)
+	m.slavoGermanic = strings.ContainsAny(s, "WK") || strings.Contains(s, "CZ")
+	index := 0
+	if m.contains(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		index = 1
+	}
+	for !m.complete() && index < len(value) {
+		switch value[index] {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if index == 0 {
+				m.add("A")
+			}
+			index++
+		case 'B':
+			m.add("P")
+			index = m.skip(index, "B")
+		case 'Ç':
+			m.add("S")
+			index++
+		case 'C':
+			index = m.c(index)
+		case 'D':
+			index = m.d(index)
+		case 'F':
+			m.add("F")
+			index = m.skip(index, "F")
+		case 'G':
+			index = m.g(index)
+		case 'H':
+			// only kept when first or between vowels
+			if (index == 0 || m.vowel(index-1)) && m.vowel(index+1) {
+				m.add("H")
+				index += 2
+			} else {
+				index++
+			}
+		case 'J':
+			index = m.j(index)
+		case 'K':
+			m.add("K")
+			index = m.skip(index, "K")
+		case 'L':
+			index = m.l(index)
+		case 'M':
+			m.add("M")
+			if m.at(index+1) == 'M' || (m.contains(index-1, 3, "UMB") && (index+1 == len(value)-1 || m.contains(index+2, 2, "ER"))) {
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.add("N")
+			index = m.skip(index, "N")
+		case 'Ñ':
+			m.add("N")
+			index++
+		case 'P':
+			if m.at(index+1) == 'H' {
+				m.add("F")
+				index += 2
+			} else {
+				m.add("P")
+				index = m.skip(index, "P", "B")
+			}
+		case 'Q':
+			m.add("K")
+			index = m.skip(index, "Q")
+		case 'R':
+			// French words ending in -ier are silent in the primary code
+			if index == len(value)-1 && !m.slavoGermanic && m.contains(index-2, 2, "IE") && !m.contains(index-4, 2, "ME", "MA") {
+				m.addAlternate("R")
+			} else {
+				m.add("R")
+			}
+			index = m.skip(index, "R")
+		case 'S':
+			index = m.s(index)
+		case 'T':
+			index = m.t(index)
+		case 'V':
+			m.add("F")
+			index = m.skip(index, "V")
+		case 'W':
+			index = m.w(index)
+		case 'X':
+			index = m.x(index)
+		case 'Z':
+			index = m.z(index)
+		default:
+			index++
+		}
+	}
+	return string(m.primary), string(m.alternate)
+}
+
+// doubleMetaphone is the state of the encoding of a word
+type doubleMetaphone struct {
+	value         []rune
+	slavoGermanic bool
+	maxLen        int
+	primary       []rune
+	alternate     []rune
+}
+
+// at returns the character at index or 0 when out of range
+func (m *doubleMetaphone) at(index int) rune {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+func (m *doubleMetaphone) vowel(index int) bool {
+	return strings.ContainsRune("AEIOUY", m.at(index))
+}
+
+// contains reports whether the length characters from start are any of values
+func (m *doubleMetaphone) contains(start int, length int, values ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	s := string(m.value[start : start+length])
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// skip returns the index after the character at index, also
+// skipping the next character when it is any of next
+func (m *doubleMetaphone) skip(index int, next ...string) int {
+	if m.contains(index+1, 1, next...) {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *doubleMetaphone) complete() bool {
+	return len(m.primary) >= m.maxLen && len(m.alternate) >= m.maxLen
+}
+
+func (m *doubleMetaphone) addPrimary(s string) {
+	for _, r := range s {
+		if len(m.primary) < m.maxLen {
+			m.primary = append(m.primary, r)
+		}
+	}
+}
+
+func (m *doubleMetaphone) addAlternate(s string) {
+	for _, r := range s {
+		if len(m.alternate) < m.maxLen {
+			m.alternate = append(m.alternate, r)
+		}
+	}
+}
+
+// add appends to both codes, or codes[0] to the primary
+// code and codes[1] to the alternate code when given
+func (m *doubleMetaphone) add(codes ...string) {
+	m.addPrimary(codes[0])
+	m.addAlternate(codes[len(codes)-1])
+}
+
+func (m *doubleMetaphone) germanic() bool {
+	return m.contains(0, 4, "VAN ", "VON ") || m.contains(0, 3, "SCH")
+}
+
+func (m *doubleMetaphone) c(index int) int {
+	switch {
+	case m.cAsK(index):
+		// various germanic
+		m.add("K")
+		return index + 2
+	case index == 0 && m.contains(index, 6, "CAESAR"):
+		m.add("S")
+		return index + 2
+	case m.contains(index, 2, "CH"):
+		return m.ch(index)
+	case m.contains(index, 2, "CZ") && !m.contains(index-2, 4, "WICZ"):
+		// Czerny
+		m.add("S", "X")
+		return index + 2
+	case m.contains(index+1, 3, "CIA"):
+		// focaccia
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		// double cc but not McClelland
+		if m.contains(index+2, 1, "I", "E", "H") && !m.contains(index+2, 2, "HU") {
+			if (index == 1 && m.at(index-1) == 'A') || m.contains(index-1, 5, "UCCEE", "UCCES") {
+				// accident, accede, succeed
+				m.add("KS")
+			} else {
+				// bacci, bertucci
+				m.add("X")
+			}
+			return index + 3
+		}
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CK", "CG", "CQ"):
+		m.add("K")
+		return index + 2
+	case m.contains(index, 2, "CI", "CE", "CY"):
+		// Italian and English
+		if m.contains(index, 3, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return index + 2
+	}
+	m.add("K")
+	switch {
+	case m.contains(index+1, 2, " C", " Q", " G"):
+		// Mac Caffrey, Mac Gregor
+		return index + 3
+	case m.contains(index+1, 1, "C", "K", "Q") && !m.contains(index+1, 2, "CE", "CI"):
+		return index + 2
+	}
+	return index + 1
+}
+
+// cAsK reports whether the C at index is pronounced K, as in Bacher
+func (m *doubleMetaphone) cAsK(index int) bool {
+	if m.contains(index, 4, "CHIA") {
+		return true
+	}
+	if index <= 1 || m.vowel(index-2) || !m.contains(index-1, 3, "ACH") {
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.contains(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *doubleMetaphone) ch(index int) int {
+	switch {
+	case index > 0 && m.contains(index, 4, "CHAE"):
+		// Michael
+		m.add("K", "X")
+	case index == 0 && (m.contains(index+1, 5, "HARAC", "HARIS") || m.contains(index+1, 3, "HOR", "HYM", "HIA", "HEM")) && !m.contains(0, 5, "CHORE"):
+		// Greek roots such as chemistry and chorus
+		m.add("K")
+	case m.germanic() || m.contains(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") || m.contains(index+2, 1, "T", "S") ||
+		((m.contains(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.contains(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || index+1 == len(m.value)-1)):
+		// germanic, Greek or otherwise pronounced kh
+		m.add("K")
+	case index > 0 && m.contains(0, 2, "MC"):
+		m.add("K")
+	case index > 0:
+		m.add("X", "K")
+	default:
+		m.add("X")
+	}
+	return index + 2
+}
+
+func (m *doubleMetaphone) d(index int) int {
+	switch {
+	case m.contains(index, 2, "DG"):
+		if m.contains(index+2, 1, "I", "E", "Y") {
+			// edge
+			m.add("J")
+			return index + 3
+		}
+		// Edgar
+		m.add("TK")
+		return index + 2
+	case m.contains(index, 2, "DT", "DD"):
+		m.add("T")
+		return index + 2
+	}
+	m.add("T")
+	return index + 1
+}
+
+func (m *doubleMetaphone) g(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.gh(index)
+	case m.at(index+1) == 'N':
+		switch {
+		case index == 1 && m.vowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.contains(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic:
+			// not e.g. cagney
+			m.add("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return index + 2
+	case m.contains(index+1, 2, "LI") && !m.slavoGermanic:
+		// tagliaro
+		m.add("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' || m.contains(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel-, -gie- at the start
+		m.add("K", "J")
+		return index + 2
+	case (m.contains(index+1, 2, "ER") || m.at(index+1) == 'Y') && !m.contains(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.contains(index-1, 1, "E", "I") && !m.contains(index-1, 3, "RGY", "OGY"):
+		// -ger-, -gy-
+		m.add("K", "J")
+		return index + 2
+	case m.contains(index+1, 1, "E", "I", "Y") || m.contains(index-1, 4, "AGGI", "OGGI"):
+		// Italian biaggi
+		switch {
+		case m.germanic() || m.contains(index+1, 2, "ET"):
+			m.add("K")
+		case m.contains(index+1, 3, "IER"):
+			m.add("J")
+		default:
+			m.add("J", "K")
+		}
+		return index + 2
+	case m.at(index+1) == 'G':
+		m.add("K")
+		return index + 2
+	}
+	m.add("K")
+	return index + 1
+}
+
+func (m *doubleMetaphone) gh(index int) int {
+	switch {
+	case index > 0 && !m.vowel(index-1):
+		m.add("K")
+	case index == 0:
+		if m.at(index+2) == 'I' {
+			m.add("J")
+		} else {
+			m.add("K")
+		}
+	case (index > 1 && m.contains(index-2, 1, "B", "H", "D")) ||
+		(index > 2 && m.contains(index-3, 1, "B", "H", "D")) ||
+		(index > 3 && m.contains(index-4, 1, "B", "H")):
+		// Parker's rule, e.g. hugh
+	case index > 2 && m.at(index-1) == 'U' && m.contains(index-3, 1, "C", "G", "L", "R", "T"):
+		// laugh, McLaughlin, cough, rough, tough
+		m.add("F")
+	case index > 0 && m.at(index-1) != 'I':
+		m.add("K")
+	}
+	return index + 2
+}
+
+func (m *doubleMetaphone) j(index int) int {
+	if m.contains(index, 4, "JOSE") || m.contains(0, 4, "SAN ") {
+		// Spanish, Jose, San Jacinto
+		if (index == 0 && m.at(index+4) == ' ') || len(m.value) == 4 || m.contains(0, 4, "SAN ") {
+			m.add("H")
+		} else {
+			m.add("J", "H")
+		}
+		return index + 1
+	}
+	switch {
+	case index == 0:
+		// Jankelowicz and Yankelovich
+		m.add("J", "A")
+	case m.vowel(index-1) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		// Spanish pronunciation, e.g. bajador
+		m.add("J", "H")
+	case index == len(m.value)-1:
+		m.addPrimary("J")
+	case !m.contains(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.contains(index-1, 1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(index, "J")
+}
+
+func (m *doubleMetaphone) l(index int) int {
+	if m.at(index+1) != 'L' {
+		m.add("L")
+		return index + 1
+	}
+	last := len(m.value) - 1
+	// Spanish, e.g. cabrillo and gallegos
+	if (index == last-2 && m.contains(index-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((m.contains(last-1, 2, "AS", "OS") || m.contains(last, 1, "A", "O")) && m.contains(index-1, 4, "ALLE")) {
+		m.addPrimary("L")
+	} else {
+		m.add("L")
+	}
+	return index + 2
+}
+
+func (m *doubleMetaphone) s(index int) int {
+	switch {
+	case m.contains(index-1, 3, "ISL", "YSL"):
+		// island, isle, carlisle, carlysle
+		return index + 1
+	case index == 0 && m.contains(index, 5, "SUGAR"):
+		m.add("X", "S")
+		return index + 1
+	case m.contains(index, 2, "SH"):
+		if m.contains(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// germanic
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return index + 2
+	case m.contains(index, 3, "SIO", "SIA") || m.contains(index, 4, "SIAN"):
+		// Italian and Armenian
+		if m.slavoGermanic {
+			m.add("S")
+		} else {
+			m.add("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.contains(index+1, 1, "M", "N", "L", "W")) || m.contains(index+1, 1, "Z"):
+		// german and anglicisations, smith matches schmidt and snider
+		// matches schneider, also -sz- in slavic languages
+		m.add("S", "X")
+		return m.skip(index, "Z")
+	case m.contains(index, 2, "SC"):
+		return m.sc(index)
+	}
+	if index == len(m.value)-1 && m.contains(index-2, 2, "AI", "OI") {
+		// French, e.g. resnais and artois
+		m.addAlternate("S")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "S", "Z")
+}
+
+func (m *doubleMetaphone) sc(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		// Schlesinger's rule
+		switch {
+		case m.contains(index+3, 2, "ER", "EN"):
+			// schermerhorn, schenker
+			m.add("X", "SK")
+		case m.contains(index+3, 2, "OO", "UY", "ED", "EM"):
+			// Dutch origin, e.g. school and schooner
+			m.add("SK")
+		case index == 0 && !m.vowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.add("X")
+		}
+	case m.contains(index+2, 1, "I", "E", "Y"):
+		m.add("S")
+	default:
+		m.add("SK")
+	}
+	return index + 3
+}
+
+func (m *doubleMetaphone) t(index int) int {
+	switch {
+	case m.contains(index, 4, "TION") || m.contains(index, 3, "TIA", "TCH"):
+		m.add("X")
+		return index + 3
+	case m.contains(index, 2, "TH") || m.contains(index, 3, "TTH"):
+		// thomas, thames or germanic
+		if m.contains(index+2, 2, "OM", "AM") || m.germanic() {
+			m.add("T")
+		} else {
+			m.add("0", "T")
+		}
+		return index + 2
+	}
+	m.add("T")
+	return m.skip(index, "T", "D")
+}
+
+func (m *doubleMetaphone) w(index int) int {
+	switch {
+	case m.contains(index, 2, "WR"):
+		m.add("R")
+		return index + 2
+	case index == 0 && m.vowel(index+1):
+		// Wasserman matches Vasserman
+		m.add("A", "F")
+	case index == 0 && m.contains(index, 2, "WH"):
+		// Uomo matches Womo
+		m.add("A")
+	case (index == len(m.value)-1 && m.vowel(index-1)) || m.contains(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.contains(0, 3, "SCH"):
+		// Arnow matches Arnoff
+		m.addAlternate("F")
+	case m.contains(index, 4, "WICZ", "WITZ"):
+		// Polish, e.g. filipowicz
+		m.add("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *doubleMetaphone) x(index int) int {
+	if index == 0 {
+		m.add("S")
+		return index + 1
+	}
+	// French, e.g. breaux
+	if !(index == len(m.value)-1 && (m.contains(index-3, 3, "IAU", "EAU") || m.contains(index-2, 2, "AU", "OU"))) {
+		m.add("KS")
+	}
+	return m.skip(index, "C", "X")
+}
+
+func (m *doubleMetaphone) z(index int) int {
+	if m.at(index+1) == 'H' {
+		// Chinese pinyin, e.g. zhao
+		m.add("J")
+		return index + 2
+	}
+	if m.contains(index+1, 2, "ZO", "ZI", "ZA") || (m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.add("S", "TS")
+	} else {
+		m.add("S")
+	}
+	return m.skip(index, "Z")
+}
@@ -0,0 +1,38 @@
+package analyser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSoundex(t *testing.T) {
+	for word, want := range map[string]string{
+		"Robert": "R163", "Rupert": "R163", "Ashcraft": "A261", "Tymczak": "T522",
+		"Pfister": "P236", "Honeyman": "H555", "Stroustrup": "S362", "strostrup": "S362",
+		"Lee": "L000", "O'Hara": "O600", "": "", "123": "",
+	} {
+		assert.Equal(t, want, Soundex(word), word)
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	for word, want := range map[string][2]string{
+		"Stroustrup": {"STRS", "STRS"}, "Strostrup": {"STRS", "STRS"},
+		"Smith": {"SM0", "XMT"}, "Schmidt": {"XMT", "SMT"}, "Thompson": {"TMPS", "TMPS"},
+		"Knuth": {"N0", "NT"}, "Jose": {"HS", "HS"}, "Xavier": {"SF", "SFR"},
+		"Michael": {"MKL", "MXL"}, "Gallegos": {"KLKS", "KKS"}, "Dumb": {"TM", "TM"},
+		"Caesar": {"SSR", "SSR"}, "Thomas": {"TMS", "TMS"}, "Arnow": {"ARN", "ARNF"},
+		"Filipowicz": {"FLPT", "FLPF"}, "Edge": {"AJ", "AJ"}, "Edgar": {"ATKR", "ATKR"},
+		"Laugh": {"LF", "LF"}, "Wasserman": {"ASRM", "FSRM"}, "Bacchus": {"PKS", "PKS"},
+		"Accident": {"AKST", "AKST"}, "Chorus": {"KRS", "KRS"}, "School": {"SKL", "SKL"},
+		"Schenker": {"XNKR", "SKNK"}, "Zhao": {"J", "J"}, "Breaux": {"PR", "PR"},
+		"": {"", ""},
+	} {
+		primary, alternate := DoubleMetaphone(word, 4)
+		assert.Equal(t, want, [2]string{primary, alternate}, word)
+	}
+
+	// longer codes
+	primary, _ := DoubleMetaphone("Stroustrup", 8)
+	assert.Equal(t, "STRSTRP", primary)
+}
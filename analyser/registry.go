@@ -0,0 +1,187 @@
+package analyser
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// DefaultAnalyser is the analyser used by text fields
+// that do not declare one in their mapping
+const DefaultAnalyser = "standard"
+
+type (
+	CharFilterFactory  func(cfg Config) (CharFilter, error)
+	TokenizerFactory   func(cfg Config) (Tokenizer, error)
+	TokenFilterFactory func(cfg Config) (TokenFilter, error)
+	AnalyserFactory    func(cfg Config) (*FullTextAnalyser, error)
+	NormalizerFactory  func(cfg Config) (*KeywordAnalyser, error)
+)
+
+// built-in analysis components by name
+var (
+	charFilters = map[string]CharFilterFactory{
+		"html_strip":      htmlStripFilterFromConfig,
+		"pattern_replace": patternReplaceFilterFromConfig,
+	}
+
+	tokenizers = map[string]TokenizerFactory{
+		"standard": func(cfg Config) (Tokenizer, error) {
+			return StandardTokenizer{}, nil
+		},
+		"uax_url_email": func(cfg Config) (Tokenizer, error) {
+			return StandardTokenizer{URLEmail: true}, nil
+		},
+		"whitespace": func(cfg Config) (Tokenizer, error) {
+			return WhitespaceTokenizer{}, nil
+		},
+		"pattern":        patternTokenizerFromConfig,
+		"path_hierarchy": pathHierarchyTokenizerFromConfig,
+		"ngram": func(cfg Config) (Tokenizer, error) {
+			return nGramTokenizerFromConfig(cfg, false)
+		},
+		"edge_ngram": func(cfg Config) (Tokenizer, error) {
+			return nGramTokenizerFromConfig(cfg, true)
+		},
+	}
+
+	tokenFilters = map[string]TokenFilterFactory{
+		"lowercase": func(cfg Config) (TokenFilter, error) {
+			return LowercaseFilter{}, nil
+		},
+		"trim": func(cfg Config) (TokenFilter, error) {
+			return TrimFilter{}, nil
+		},
+		"asciifolding": func(cfg Config) (TokenFilter, error) {
+			preserve, err := cfg.Bool("preserve_original", false)
+			return ASCIIFoldingFilter{PreserveOriginal: preserve}, err
+		},
+		"stop": func(cfg Config) (TokenFilter, error) {
+			return stopFilterFromConfig(cfg, "_english_")
+		},
+		"keyword_marker": func(cfg Config) (TokenFilter, error) {
+			keywords, err := cfg.Strings("keywords")
+			if err != nil {
+				return nil, err
+			}
+			ignoreCase, err := cfg.Bool("ignore_case", false)
+			return NewKeywordMarkerFilter(keywords, ignoreCase), err
+		},
+		"stemmer":  stemmerFilterFromConfig,
+		"snowball": stemmerFilterFromConfig,
+		"ngram": func(cfg Config) (TokenFilter, error) {
+			return nGramFilterFromConfig(cfg, false)
+		},
+		"edge_ngram": func(cfg Config) (TokenFilter, error) {
+			return nGramFilterFromConfig(cfg, true)
+		},
+		"cjk_bigram": cjkBigramFilterFromConfig,
+		"cjk_width": func(cfg Config) (TokenFilter, error) {
+			return CJKWidthFilter{}, nil
+		},
+		"phonetic":       phoneticFilterFromConfig,
+		"shingle":        shingleFilterFromConfig,
+		"word_delimiter": wordDelimiterFilterFromConfig,
+		"synonym":        synonymFilterFromConfig,
+		"synonym_graph":  synonymFilterFromConfig,
+		"nfc": func(cfg Config) (TokenFilter, error) {
+			return NormalizationFilter{Form: norm.NFC}, nil
+		},
+		"nfd": func(cfg Config) (TokenFilter, error) {
+			return NormalizationFilter{Form: norm.NFD}, nil
+		},
+		"nfkc": func(cfg Config) (TokenFilter, error) {
+			return NormalizationFilter{Form: norm.NFKC}, nil
+		},
+		"nfkd": func(cfg Config) (TokenFilter, error) {
+			return NormalizationFilter{Form: norm.NFKD}, nil
+		},
+	}
+
+	// token filters that may be used by normalizers, which
+	// transform terms without splitting them
+	normalizerFilters = map[string]bool{
+		"lowercase": true, "asciifolding": true, "trim": true,
+		"nfc": true, "nfd": true, "nfkc": true, "nfkd": true,
+	}
+
+	normalizers = map[string]NormalizerFactory{
+		"lowercase": func(cfg Config) (*KeywordAnalyser, error) {
+			return &KeywordAnalyser{TokenFilters: []TokenFilter{LowercaseFilter{}}}, nil
+		},
+	}
+
+	analysers = map[string]AnalyserFactory{
+		"standard": func(cfg Config) (*FullTextAnalyser, error) {
+			return standardAnalyser(cfg, "_none_")
+		},
+		"stop": func(cfg Config) (*FullTextAnalyser, error) {
+			return standardAnalyser(cfg, "_english_")
+		},
+		"english": func(cfg Config) (*FullTextAnalyser, error) {
+			a, err := standardAnalyser(cfg, "_english_")
+			if err != nil {
+				return nil, err
+			}
+			exclusions, err := cfg.Strings("stem_exclusion")
+			if err != nil {
+				return nil, err
+			}
+			if len(exclusions) > 0 {
+				a.TokenFilters = append(a.TokenFilters, NewKeywordMarkerFilter(exclusions, true))
+			}
+			a.TokenFilters = append(a.TokenFilters, StemmerFilter{Stem: StemEnglish})
+			return a, nil
+		},
+		"whitespace": func(cfg Config) (*FullTextAnalyser, error) {
+			return &FullTextAnalyser{Tokenizer: WhitespaceTokenizer{}}, nil
+		},
+		"cjk": func(cfg Config) (*FullTextAnalyser, error) {
+			bigram, err := cjkBigramFilterFromConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			stop, err := stopFilterFromConfig(cfg, "_english_")
+			if err != nil {
+				return nil, err
+			}
+			return &FullTextAnalyser{
+				Tokenizer:    StandardTokenizer{},
+				TokenFilters: []TokenFilter{CJKWidthFilter{}, LowercaseFilter{}, bigram, stop},
+			}, nil
+		},
+	}
+)
+
+// NewAnalyser creates the built-in analyser name using cfg for
+// any parameters it accepts. The custom analyser builds a chain
+// of built-in components with their default parameters from the
+// char_filter, tokenizer and filter names in cfg
+func NewAnalyser(name string, cfg Config) (*FullTextAnalyser, error) {
+	return Analysis{}.NewAnalyser(name, cfg)
+}
+
+// standardAnalyser lower cases tokens from the standard tokenizer
+// and removes the stop words given in cfg, or stopWords by default
+func standardAnalyser(cfg Config, stopWords string) (*FullTextAnalyser, error) {
+	a := &FullTextAnalyser{
+		Tokenizer:    StandardTokenizer{},
+		TokenFilters: []TokenFilter{LowercaseFilter{}},
+	}
+	stop, err := stopFilterFromConfig(cfg, stopWords)
+	if err != nil {
+		return nil, err
+	}
+	if len(stop.Words) > 0 {
+		a.TokenFilters = append(a.TokenFilters, stop)
+	}
+	return a, nil
+}
+
+// stemmerFilterFromConfig creates a StemmerFilter for the language parameter
+func stemmerFilterFromConfig(cfg Config) (TokenFilter, error) {
+	language, err := cfg.String("language", "english")
+	if err != nil {
+		return nil, err
+	}
+	return NewStemmerFilter(strings.ToLower(language))
+}
@@ -0,0 +1,101 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type appendCharFilter struct{}
+
+func (appendCharFilter) Filter(content string) (string, OffsetMap) {
+	return content + " z", OffsetMap{}
+}
+
+type dropFirstFilter struct{}
+
+func (dropFirstFilter) Filter(tokens []Token) []Token {
+	return tokens[1:]
+}
+
+func TestFullTextAnalyser_Chain(t *testing.T) {
+	a := FullTextAnalyser{
+		CharFilters:  []CharFilter{appendCharFilter{}},
+		Tokenizer:    WhitespaceTokenizer{},
+		TokenFilters: []TokenFilter{dropFirstFilter{}},
+	}
+	r, err := a.Analyse("a b")
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{{Term: "b", Position: 1, Start: 2, End: 3, Type: TypeWord}, {Term: "z", Position: 2, Start: 4, End: 5, Type: TypeWord}}, r)
+}
+
+func TestNewAnalyser(t *testing.T) {
+	a, err := NewAnalyser(DefaultAnalyser, nil)
+	assert.Nil(t, err)
+	r, err := a.Terms("a b")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, r)
+
+	// unknown analyser
+	_, err = NewAnalyser("notexists", nil)
+	assert.Equal(t, errors.New("unknown analyser notexists"), err)
+
+	// custom analyser
+	a, err = NewAnalyser("custom", Config{"tokenizer": "whitespace"})
+	assert.Nil(t, err)
+	assert.Equal(t, &FullTextAnalyser{Tokenizer: WhitespaceTokenizer{}}, a)
+
+	// custom analyser missing tokenizer
+	_, err = NewAnalyser("custom", Config{})
+	assert.Equal(t, errors.New("custom analyser requires a tokenizer"), err)
+
+	// custom analyser unknown components
+	_, err = NewAnalyser("custom", Config{"tokenizer": "notexists"})
+	assert.Equal(t, errors.New("unknown tokenizer notexists"), err)
+	_, err = NewAnalyser("custom", Config{"tokenizer": "whitespace", "char_filter": "notexists"})
+	assert.Equal(t, errors.New("unknown char filter notexists"), err)
+	_, err = NewAnalyser("custom", Config{"tokenizer": "whitespace", "filter": []interface{}{"notexists"}})
+	assert.Equal(t, errors.New("unknown token filter notexists"), err)
+}
+
+func TestConfig(t *testing.T) {
+	c := Config{"a": "x, y,,z", "b": []interface{}{"x"}, "c": 1, "d": []interface{}{1}}
+
+	s, err := c.String("a", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "x, y,,z", s)
+	s, err = c.String("notexists", "def")
+	assert.Nil(t, err)
+	assert.Equal(t, "def", s)
+	_, err = c.String("c", "")
+	assert.Equal(t, errors.New("expected string for c"), err)
+
+	l, err := c.Strings("a")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "y", "z"}, l)
+	l, err = c.Strings("b")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x"}, l)
+	l, err = c.Strings("notexists")
+	assert.Nil(t, err)
+	assert.Nil(t, l)
+	_, err = c.Strings("c")
+	assert.Equal(t, errors.New("expected array of strings for c"), err)
+	_, err = c.Strings("d")
+	assert.Equal(t, errors.New("expected array of strings for d"), err)
+
+	c = Config{"a": true, "b": "false", "c": "x", "d": 1}
+	b, err := c.Bool("a", false)
+	assert.Nil(t, err)
+	assert.True(t, b)
+	b, err = c.Bool("b", true)
+	assert.Nil(t, err)
+	assert.False(t, b)
+	b, err = c.Bool("notexists", true)
+	assert.Nil(t, err)
+	assert.True(t, b)
+	_, err = c.Bool("c", false)
+	assert.Equal(t, errors.New("expected boolean for c"), err)
+	_, err = c.Bool("d", false)
+	assert.Equal(t, errors.New("expected boolean for d"), err)
+}
@@ -0,0 +1,307 @@
+package analyser
+
+import (
+	"unicode"
+)
+
+// words with irregular stems
+var englishExceptions = map[string]string{
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+	"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli",
+	"singly": "singl", "sky": "sky", "news": "news", "howe": "howe", "atlas": "atlas",
+	"cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// words left unchanged once plurals are removed
+var englishInvariants = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+type suffixRule struct {
+	suffix      string
+	replacement string
+}
+
+// suffix rules are ordered longest first
+var englishStep2 = []suffixRule{
+	{"ization", "ize"}, {"ational", "ate"}, {"fulness", "ful"}, {"ousness", "ous"}, {"iveness", "ive"},
+	{"tional", "tion"}, {"biliti", "ble"}, {"lessli", "less"},
+	{"entli", "ent"}, {"ation", "ate"}, {"alism", "al"}, {"aliti", "al"}, {"ousli", "ous"},
+	{"iviti", "ive"}, {"fulli", "ful"},
+	{"enci", "ence"}, {"anci", "ance"}, {"abli", "able"}, {"izer", "ize"}, {"ator", "ate"}, {"alli", "al"},
+	{"bli", "ble"}, {"ogi", "og"},
+	{"li", ""},
+}
+
+var englishStep3 = []suffixRule{
+	{"ational", "ate"},
+	{"tional", "tion"},
+	{"alize", "al"}, {"icate", "ic"}, {"iciti", "ic"}, {"ative", ""},
+	{"ical", "ic"}, {"ness", ""},
+	{"ful", ""},
+}
+
+var englishStep4 = []string{
+	"ement",
+	"ance", "ence", "able", "ible", "ment",
+	"ant", "ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+	"al", "er", "ic",
+}
+
+// StemEnglish reduces an English word to its stem using the Porter2
+// (Snowball English) algorithm. The word is expected to be lower case.
+// Words containing non ASCII characters are returned unchanged
+func StemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] > unicode.MaxASCII {
+			return word
+		}
+	}
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+
+	w := []byte(word)
+	if w[0] == '\'' {
+		w = w[1:]
+	}
+	// y is a consonant at the start of a word or after a vowel
+	for i := range w {
+		if w[i] == 'y' && (i == 0 || isVowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+
+	r1, r2 := englishRegions(w)
+
+	w = englishStep0(w)
+	w = englishStep1a(w)
+	if englishInvariants[string(w)] {
+		return string(w)
+	}
+	w = englishStep1b(w, r1)
+	w = englishStep1c(w)
+	w = englishSuffixRules(w, englishStep2, func(w []byte, rule suffixRule) bool {
+		if len(w)-len(rule.suffix) < r1 {
+			return false
+		}
+		pre := w[:len(w)-len(rule.suffix)]
+		switch rule.suffix {
+		case "ogi":
+			return len(pre) > 0 && pre[len(pre)-1] == 'l'
+		case "li":
+			return len(pre) > 0 && isValidLiEnding(pre[len(pre)-1])
+		}
+		return true
+	})
+	w = englishSuffixRules(w, englishStep3, func(w []byte, rule suffixRule) bool {
+		if rule.suffix == "ative" {
+			return len(w)-len(rule.suffix) >= r2
+		}
+		return len(w)-len(rule.suffix) >= r1
+	})
+	w = englishStep4Rules(w, r2)
+	w = englishStep5(w, r1, r2)
+
+	for i := range w {
+		if w[i] == 'Y' {
+			w[i] = 'y'
+		}
+	}
+	return string(w)
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+func isDouble(w []byte) bool {
+	n := len(w)
+	if n < 2 || w[n-1] != w[n-2] {
+		return false
+	}
+	switch w[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func isValidLiEnding(c byte) bool {
+	switch c {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+func containsVowel(w []byte) bool {
+	for _, c := range w {
+		if isVowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsShortSyllable reports whether w ends with a vowel followed by a
+// non-vowel other than w, x or Y and preceded by a non-vowel, or is a
+// vowel followed by a non-vowel
+func endsShortSyllable(w []byte) bool {
+	n := len(w)
+	switch {
+	case n == 2:
+		return isVowel(w[0]) && !isVowel(w[1])
+	case n > 2:
+		c := w[n-1]
+		return !isVowel(w[n-3]) && isVowel(w[n-2]) && !isVowel(c) && c != 'w' && c != 'x' && c != 'Y'
+	}
+	return false
+}
+
+// englishRegions finds R1, the region after the first non-vowel following
+// a vowel, and R2, the same region within R1
+func englishRegions(w []byte) (int, int) {
+	region := func(start int) int {
+		for i := start + 1; i < len(w); i++ {
+			if !isVowel(w[i]) && isVowel(w[i-1]) {
+				return i + 1
+			}
+		}
+		return len(w)
+	}
+	r1 := -1
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if len(w) >= len(prefix) && string(w[:len(prefix)]) == prefix {
+			r1 = len(prefix)
+		}
+	}
+	if r1 == -1 {
+		r1 = region(0)
+	}
+	return r1, region(r1)
+}
+
+func englishStep0(w []byte) []byte {
+	for _, suffix := range []string{"'s'", "'s", "'"} {
+		if hasSuffix(w, suffix) {
+			return w[:len(w)-len(suffix)]
+		}
+	}
+	return w
+}
+
+func englishStep1a(w []byte) []byte {
+	n := len(w)
+	switch {
+	case hasSuffix(w, "sses"):
+		return w[:n-2]
+	case hasSuffix(w, "ied"), hasSuffix(w, "ies"):
+		if n > 4 {
+			return w[:n-2]
+		}
+		return w[:n-1]
+	case hasSuffix(w, "us"), hasSuffix(w, "ss"):
+		return w
+	case hasSuffix(w, "s"):
+		if containsVowel(w[:n-2]) {
+			return w[:n-1]
+		}
+	}
+	return w
+}
+
+func englishStep1b(w []byte, r1 int) []byte {
+	for _, suffix := range []string{"eedly", "ingly", "edly", "eed", "ing", "ed"} {
+		if !hasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if suffix == "eed" || suffix == "eedly" {
+			if len(stem) >= r1 {
+				return append(stem, 'e', 'e')
+			}
+			return w
+		}
+		if !containsVowel(stem) {
+			return w
+		}
+		switch {
+		case hasSuffix(stem, "at"), hasSuffix(stem, "bl"), hasSuffix(stem, "iz"):
+			return append(stem, 'e')
+		case isDouble(stem):
+			return stem[:len(stem)-1]
+		case endsShortSyllable(stem) && r1 >= len(stem):
+			return append(stem, 'e')
+		}
+		return stem
+	}
+	return w
+}
+
+func englishStep1c(w []byte) []byte {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+	return w
+}
+
+// englishSuffixRules replaces the longest matching suffix
+// of w when the condition for the rule is met
+func englishSuffixRules(w []byte, rules []suffixRule, cond func(w []byte, rule suffixRule) bool) []byte {
+	for _, rule := range rules {
+		if !hasSuffix(w, rule.suffix) {
+			continue
+		}
+		if cond(w, rule) {
+			return append(w[:len(w)-len(rule.suffix)], rule.replacement...)
+		}
+		return w
+	}
+	return w
+}
+
+func englishStep4Rules(w []byte, r2 int) []byte {
+	for _, suffix := range englishStep4 {
+		if !hasSuffix(w, suffix) {
+			continue
+		}
+		stem := w[:len(w)-len(suffix)]
+		if len(stem) < r2 {
+			return w
+		}
+		if suffix == "ion" && !hasSuffix(stem, "s") && !hasSuffix(stem, "t") {
+			return w
+		}
+		return stem
+	}
+	return w
+}
+
+func englishStep5(w []byte, r1 int, r2 int) []byte {
+	n := len(w)
+	switch {
+	case hasSuffix(w, "e"):
+		if n-1 >= r2 || (n-1 >= r1 && !endsShortSyllable(w[:n-1])) {
+			return w[:n-1]
+		}
+	case hasSuffix(w, "l"):
+		if n-1 >= r2 && hasSuffix(w[:n-1], "l") {
+			return w[:n-1]
+		}
+	}
+	return w
+}
@@ -0,0 +1,35 @@
+package analyser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStemEnglish(t *testing.T) {
+	// sample of the Snowball English vocabulary and expected stems
+	for word, want := range map[string]string{
+		"a": "a", "run": "run", "runs": "run", "running": "run", "ran": "ran",
+		"consign": "consign", "consigned": "consign", "consigning": "consign", "consignment": "consign",
+		"consist": "consist", "consisted": "consist", "consistency": "consist", "consistent": "consist",
+		"consistently": "consist", "consisting": "consist", "consists": "consist",
+		"consolation": "consol", "consolations": "consol", "consolatory": "consolatori",
+		"console": "consol", "consoled": "consol", "consoles": "consol", "consolidate": "consolid",
+		"consolidated": "consolid", "consolidating": "consolid", "consoling": "consol",
+		"consolingly": "consol", "consols": "consol", "consonant": "conson", "consort": "consort",
+		"conspicuous": "conspicu", "conspicuously": "conspicu", "conspiracy": "conspiraci",
+		"conspirator": "conspir", "conspirators": "conspir", "conspire": "conspir",
+		"constable": "constabl", "constables": "constabl", "constance": "constanc",
+		"constancy": "constanc", "constant": "constant",
+		"knackeries": "knackeri", "knaves": "knave", "knavish": "knavish", "kneaded": "knead",
+		"knee": "knee", "kneeling": "kneel", "knees": "knee", "knightly": "knight",
+		"knitted": "knit", "knitting": "knit", "knives": "knive", "knocker": "knocker",
+		"caresses": "caress", "ponies": "poni", "ties": "tie", "cries": "cri", "gas": "gas",
+		"gaps": "gap", "kiwis": "kiwi", "agreed": "agre", "feed": "feed", "hoping": "hope",
+		"hopping": "hop", "happy": "happi", "relational": "relat", "generously": "generous",
+		"communism": "communism", "skies": "sky", "dying": "die", "news": "news",
+		"succeeding": "succeed", "inning": "inning", "cat's": "cat", "'tis": "tis",
+		"café": "café",
+	} {
+		assert.Equal(t, want, StemEnglish(word), word)
+	}
+}
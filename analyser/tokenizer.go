@@ -1,19 +1,41 @@
 package analyser
 
 import (
-	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-type Tokenizer struct{}
+// Tokenizer splits text into a stream of tokens
+type Tokenizer interface {
+	Tokenize(content string) []Token
+}
+
+type WhitespaceTokenizer struct{}
 
-// Tokenize splits a string into an array of strings using
+// Tokenize splits a string into tokens using
 // white space characters, as defined by unicode.IsSpace
-func (t Tokenizer) Tokenize(term string) (result []string) {
-	result = strings.Fields(term)
-	return result
+func (t WhitespaceTokenizer) Tokenize(content string) []Token {
+	var tokens []Token
+	start := -1
+	for i := 0; i <= len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if i == len(content) || unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, Token{Term: content[start:i], Position: len(tokens), Start: start, End: i, Type: TypeWord})
+				start = -1
+			}
+			if i == len(content) {
+				break
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+	return tokens
 }
 
-// NewTokenizer returns a new Tokenizer struct
+// NewTokenizer returns the default Tokenizer
 func NewTokenizer() Tokenizer {
-	return Tokenizer{}
+	return StandardTokenizer{}
 }
@@ -0,0 +1,103 @@
+package analyser
+
+import (
+	"errors"
+	"strings"
+)
+
+// PathHierarchyTokenizer emits each level of a hierarchical value such as
+// a file path, so /a/b/c produces /a, /a/b and /a/b/c. Searching for a
+// level then matches all of the values below it. All tokens are at the
+// same position
+type PathHierarchyTokenizer struct {
+	Delimiter string
+	// Replacement replaces the delimiter in tokens when set
+	Replacement string
+	// Reverse emits levels from the end of the value, so a/b/c
+	// produces a/b/c, b/c and c, for values such as domain names
+	Reverse bool
+	// Skip is the number of levels not emitted, from
+	// the end of the value when Reverse is set
+	Skip int
+}
+
+// NewPathHierarchyTokenizer creates a PathHierarchyTokenizer splitting
+// values on delimiter
+func NewPathHierarchyTokenizer(delimiter string) (PathHierarchyTokenizer, error) {
+	if delimiter == "" {
+		return PathHierarchyTokenizer{}, errors.New("path_hierarchy requires a delimiter")
+	}
+	return PathHierarchyTokenizer{Delimiter: delimiter}, nil
+}
+
+// pathHierarchyTokenizerFromConfig creates a PathHierarchyTokenizer from
+// the delimiter, by default /, replacement, reverse and skip parameters
+func pathHierarchyTokenizerFromConfig(cfg Config) (Tokenizer, error) {
+	delimiter, err := cfg.String("delimiter", "/")
+	if err != nil {
+		return nil, err
+	}
+	t, err := NewPathHierarchyTokenizer(delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if t.Replacement, err = cfg.String("replacement", ""); err != nil {
+		return nil, err
+	}
+	if t.Reverse, err = cfg.Bool("reverse", false); err != nil {
+		return nil, err
+	}
+	if t.Skip, err = cfg.Int("skip", 0); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t PathHierarchyTokenizer) Tokenize(content string) []Token {
+	if content == "" {
+		return nil
+	}
+	// offsets of the levels, each including the delimiter before it,
+	// or after it when reversed
+	var bounds []int
+	bounds = append(bounds, 0)
+	for i := 0; ; {
+		n := strings.Index(content[i:], t.Delimiter)
+		if n < 0 {
+			break
+		}
+		i += n
+		if t.Reverse {
+			i += len(t.Delimiter)
+		}
+		bounds = append(bounds, i)
+		if !t.Reverse {
+			i += len(t.Delimiter)
+		}
+	}
+	bounds = append(bounds, len(content))
+	// an empty level at the start, or the end when reversed, is not emitted
+	if !t.Reverse && bounds[1] == 0 {
+		bounds = bounds[1:]
+	}
+	if t.Reverse && bounds[len(bounds)-2] == len(content) {
+		bounds = bounds[:len(bounds)-1]
+	}
+	levels := len(bounds) - 1
+	if t.Skip >= levels {
+		return nil
+	}
+	var tokens []Token
+	for i := 0; i < levels-t.Skip; i++ {
+		start, end := bounds[t.Skip], bounds[t.Skip+i+1]
+		if t.Reverse {
+			start, end = bounds[i], bounds[levels-t.Skip]
+		}
+		term := content[start:end]
+		if t.Replacement != "" {
+			term = strings.Replace(term, t.Delimiter, t.Replacement, -1)
+		}
+		tokens = append(tokens, Token{Term: term, Position: 0, Start: start, End: end, Type: TypeWord})
+	}
+	return tokens
+}
@@ -0,0 +1,55 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPathHierarchyTokenizer_Tokenize(t *testing.T) {
+	tk, err := NewPathHierarchyTokenizer("/")
+	assert.Nil(t, err)
+	want := []Token{
+		{Term: "/a", Position: 0, Start: 0, End: 2, Type: TypeWord},
+		{Term: "/a/bc", Position: 0, Start: 0, End: 5, Type: TypeWord},
+		{Term: "/a/bc/d", Position: 0, Start: 0, End: 7, Type: TypeWord},
+	}
+	assert.Equal(t, want, tk.Tokenize("/a/bc/d"))
+
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	for _, tcase := range []struct {
+		name      string
+		tokenizer PathHierarchyTokenizer
+		content   string
+		want      []string
+	}{
+		{"relative", PathHierarchyTokenizer{Delimiter: "/"}, "a/b/c", []string{"a", "a/b", "a/b/c"}},
+		{"trailing delimiter", PathHierarchyTokenizer{Delimiter: "/"}, "/a/b/", []string{"/a", "/a/b", "/a/b/"}},
+		{"root", PathHierarchyTokenizer{Delimiter: "/"}, "/", []string{"/"}},
+		{"no delimiter", PathHierarchyTokenizer{Delimiter: "/"}, "a", []string{"a"}},
+		{"empty", PathHierarchyTokenizer{Delimiter: "/"}, "", nil},
+		{"delimiter", PathHierarchyTokenizer{Delimiter: "::"}, "a::b", []string{"a", "a::b"}},
+		{"replacement", PathHierarchyTokenizer{Delimiter: "-", Replacement: "/"}, "a-b-c", []string{"a", "a/b", "a/b/c"}},
+		{"skip", PathHierarchyTokenizer{Delimiter: "/", Skip: 1}, "/a/b/c", []string{"/b", "/b/c"}},
+		{"skip all", PathHierarchyTokenizer{Delimiter: "/", Skip: 3}, "/a/b/c", nil},
+		{"reverse", PathHierarchyTokenizer{Delimiter: ".", Reverse: true}, "www.example.com", []string{"www.example.com", "example.com", "com"}},
+		{"reverse absolute", PathHierarchyTokenizer{Delimiter: "/", Reverse: true}, "/a/b", []string{"/a/b", "a/b", "b"}},
+		{"reverse trailing delimiter", PathHierarchyTokenizer{Delimiter: "/", Reverse: true}, "a/b/", []string{"a/b/", "b/"}},
+		{"reverse skip", PathHierarchyTokenizer{Delimiter: "/", Reverse: true, Skip: 1}, "/a/b/c", []string{"/a/b/", "a/b/", "b/"}},
+	} {
+		assert.Equal(t, tcase.want, terms(tcase.tokenizer.Tokenize(tcase.content)), tcase.name)
+	}
+
+	_, err = NewPathHierarchyTokenizer("")
+	assert.Equal(t, errors.New("path_hierarchy requires a delimiter"), err)
+
+	r, err := pathHierarchyTokenizerFromConfig(Config{"delimiter": "-", "replacement": "/", "reverse": "true", "skip": "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, PathHierarchyTokenizer{Delimiter: "-", Replacement: "/", Reverse: true, Skip: 1}, r)
+}
@@ -0,0 +1,58 @@
+package analyser
+
+import (
+	"regexp"
+)
+
+// PatternTokenizer splits text on matches of a regular expression. When
+// Group is zero or more the text of that group of each match is emitted
+// in place of the text between matches
+type PatternTokenizer struct {
+	Pattern *regexp.Regexp
+	Group   int
+}
+
+// NewPatternTokenizer creates a PatternTokenizer compiling pattern
+func NewPatternTokenizer(pattern string, group int) (PatternTokenizer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return PatternTokenizer{}, err
+	}
+	return PatternTokenizer{Pattern: re, Group: group}, nil
+}
+
+// patternTokenizerFromConfig creates a PatternTokenizer from the pattern
+// parameter, by default splitting on non word characters, and group
+func patternTokenizerFromConfig(cfg Config) (Tokenizer, error) {
+	pattern, err := cfg.String("pattern", `\W+`)
+	if err != nil {
+		return nil, err
+	}
+	group, err := cfg.Int("group", -1)
+	if err != nil {
+		return nil, err
+	}
+	return NewPatternTokenizer(pattern, group)
+}
+
+func (t PatternTokenizer) Tokenize(content string) []Token {
+	var tokens []Token
+	emit := func(start int, end int) {
+		if start < end {
+			tokens = append(tokens, Token{Term: content[start:end], Position: len(tokens), Start: start, End: end, Type: TypeWord})
+		}
+	}
+	last := 0
+	for _, m := range t.Pattern.FindAllStringSubmatchIndex(content, -1) {
+		if t.Group < 0 {
+			emit(last, m[0])
+			last = m[1]
+		} else if 2*t.Group+1 < len(m) && m[2*t.Group] >= 0 {
+			emit(m[2*t.Group], m[2*t.Group+1])
+		}
+	}
+	if t.Group < 0 {
+		emit(last, len(content))
+	}
+	return tokens
+}
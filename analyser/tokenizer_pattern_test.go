@@ -0,0 +1,57 @@
+package analyser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPatternTokenizer_Tokenize(t *testing.T) {
+	tokenizer, err := NewPatternTokenizer(`\W+`, -1)
+	assert.Nil(t, err)
+	have := tokenizer.Tokenize("GET /api/v1 200")
+	want := []Token{
+		{Term: "GET", Position: 0, Start: 0, End: 3, Type: TypeWord},
+		{Term: "api", Position: 1, Start: 5, End: 8, Type: TypeWord},
+		{Term: "v1", Position: 2, Start: 9, End: 11, Type: TypeWord},
+		{Term: "200", Position: 3, Start: 12, End: 15, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	// emit a group of each match
+	tokenizer, err = NewPatternTokenizer(`"((?:\\"|[^"])+)"`, 1)
+	assert.Nil(t, err)
+	have = tokenizer.Tokenize(`"value", "value with \"quotes\""`)
+	want = []Token{
+		{Term: "value", Position: 0, Start: 1, End: 6, Type: TypeWord},
+		{Term: `value with \"quotes\"`, Position: 1, Start: 10, End: 31, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+
+	// emit whole matches
+	tokenizer, err = NewPatternTokenizer(`[0-9]+`, 0)
+	assert.Nil(t, err)
+	have = tokenizer.Tokenize("a1b22")
+	assert.Equal(t, []Token{{Term: "1", Position: 0, Start: 1, End: 2, Type: TypeWord}, {Term: "22", Position: 1, Start: 3, End: 5, Type: TypeWord}}, have)
+
+	// missing group
+	tokenizer, err = NewPatternTokenizer(`[0-9]+`, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, tokenizer.Tokenize("a1b22"))
+}
+
+func TestPatternTokenizerFromConfig(t *testing.T) {
+	tokenizer, err := patternTokenizerFromConfig(Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, `\W+`, tokenizer.(PatternTokenizer).Pattern.String())
+	assert.Equal(t, -1, tokenizer.(PatternTokenizer).Group)
+
+	tokenizer, err = patternTokenizerFromConfig(Config{"pattern": ",", "group": "0"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, tokenizer.(PatternTokenizer).Group)
+
+	_, err = patternTokenizerFromConfig(Config{"pattern": "("})
+	assert.Equal(t, "error parsing regexp: missing closing ): `(`", err.Error())
+	_, err = patternTokenizerFromConfig(Config{"group": "x"})
+	assert.Equal(t, errors.New("expected integer for group"), err)
+}
@@ -0,0 +1,207 @@
+package analyser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token types
+const (
+	TypeWord        = "word"
+	TypeAlphaNum    = "<ALPHANUM>"
+	TypeNum         = "<NUM>"
+	TypeIdeographic = "<IDEOGRAPHIC>"
+	TypeHiragana    = "<HIRAGANA>"
+	TypeKatakana    = "<KATAKANA>"
+	TypeHangul      = "<HANGUL>"
+	TypeEmail       = "<EMAIL>"
+	TypeURL         = "<URL>"
+)
+
+// word break classes, a subset of those in Unicode Standard Annex #29
+const (
+	wbOther = iota
+	wbLetter
+	wbNumeric
+	wbKatakana
+	wbHiragana
+	wbIdeographic
+	wbExtend
+	wbExtendNumLet
+	wbMidLetter
+	wbMidNum
+	wbMidNumLet
+)
+
+var (
+	emailRegexp = regexp.MustCompile(`^[A-Za-z0-9!#$%&*+/=?^_{|}~-]+(\.[A-Za-z0-9!#$%&*+/=?^_{|}~-]+)*@([A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?\.)+[A-Za-z]{2,}`)
+	urlRegexp   = regexp.MustCompile(`^(?i:(https?|ftp|file)://|www\.)[^\s<>"'(){}\[\]]+`)
+)
+
+// StandardTokenizer splits text on word boundaries as described by
+// Unicode Standard Annex #29, discarding punctuation and white space.
+// Han and Hiragana characters are emitted as single character tokens
+type StandardTokenizer struct {
+	// URLEmail keeps email addresses and URLs as single tokens
+	URLEmail bool
+}
+
+func (t StandardTokenizer) Tokenize(content string) []Token {
+	var tokens []Token
+	for i := 0; i < len(content); {
+		if t.URLEmail {
+			if n, typ := matchURLEmail(content[i:]); n > 0 {
+				tokens = append(tokens, Token{Term: content[i : i+n], Position: len(tokens), Start: i, End: i + n, Type: typ})
+				i += n
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(content[i:])
+		switch wordBreakClass(r) {
+		case wbIdeographic, wbHiragana:
+			typ := TypeIdeographic
+			if unicode.Is(unicode.Hiragana, r) {
+				typ = TypeHiragana
+			}
+			end := skipExtend(content, i+size)
+			tokens = append(tokens, Token{Term: content[i:end], Position: len(tokens), Start: i, End: end, Type: typ})
+			i = end
+		case wbLetter, wbNumeric, wbKatakana, wbExtendNumLet:
+			end, typ := scanWord(content, i)
+			if typ != "" {
+				tokens = append(tokens, Token{Term: content[i:end], Position: len(tokens), Start: i, End: end, Type: typ})
+			}
+			i = end
+		default:
+			i += size
+		}
+	}
+	return tokens
+}
+
+// scanWord finds the end of the word starting at start and its token type.
+// The type is empty when the word contains no letters or digits
+func scanWord(content string, start int) (int, string) {
+	r, size := utf8.DecodeRuneInString(content[start:])
+	prev := wordBreakClass(r)
+	hasLetter, hasDigit, hangul := false, false, true
+	katakana := prev == wbKatakana
+	end := start
+	for {
+		switch prev {
+		case wbLetter:
+			hasLetter = true
+			hangul = hangul && unicode.Is(unicode.Hangul, r)
+		case wbNumeric:
+			hasDigit = true
+		case wbKatakana:
+			hasLetter = true
+		}
+		end = skipExtend(content, end+size)
+		if end >= len(content) {
+			break
+		}
+		r, size = utf8.DecodeRuneInString(content[end:])
+		c := wordBreakClass(r)
+		if joins(prev, c) {
+			prev = c
+			continue
+		}
+		// letters or digits either side of a mid character do not break
+		if c == wbMidLetter || c == wbMidNum || c == wbMidNumLet {
+			next := skipExtend(content, end+size)
+			if next < len(content) {
+				r2, size2 := utf8.DecodeRuneInString(content[next:])
+				c2 := wordBreakClass(r2)
+				if (prev == wbLetter && c2 == wbLetter && c != wbMidNum) ||
+					(prev == wbNumeric && c2 == wbNumeric && c != wbMidLetter) {
+					prev, r, size, end = c2, r2, size2, next
+					continue
+				}
+			}
+		}
+		break
+	}
+	switch {
+	case katakana:
+		return end, TypeKatakana
+	case hasLetter && hangul && !hasDigit:
+		return end, TypeHangul
+	case hasLetter:
+		return end, TypeAlphaNum
+	case hasDigit:
+		return end, TypeNum
+	default:
+		return end, ""
+	}
+}
+
+// joins reports whether there is no word boundary between two adjacent classes
+func joins(a int, b int) bool {
+	switch a {
+	case wbLetter, wbNumeric:
+		return b == wbLetter || b == wbNumeric || b == wbExtendNumLet
+	case wbKatakana:
+		return b == wbKatakana || b == wbExtendNumLet
+	case wbExtendNumLet:
+		return b == wbLetter || b == wbNumeric || b == wbKatakana || b == wbExtendNumLet
+	}
+	return false
+}
+
+// skipExtend returns the index of the first character at or after i
+// that is not a combining mark or format character
+func skipExtend(content string, i int) int {
+	for i < len(content) {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if wordBreakClass(r) != wbExtend {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func wordBreakClass(r rune) int {
+	switch r {
+	case '_', '\u203f', '\u2040', '\u2054', '\ufe33', '\ufe34', '\ufe4d', '\ufe4e', '\ufe4f', '\uff3f':
+		return wbExtendNumLet
+	case '\u00b7', '\u0387', '\u05f4', '\u2027', '\ufe13', '\ufe55', '\uff1a':
+		return wbMidLetter
+	case ',', ';', '\u037e', '\u0589', '\u060c', '\u060d', '\u066c', '\u07f8', '\u2044', '\ufe10', '\ufe14', '\ufe50', '\ufe54', '\uff0c', '\uff1b':
+		return wbMidNum
+	case '.', '\'', '\u2018', '\u2019', '\u2024', '\ufe52', '\uff07', '\uff0e':
+		return wbMidNumLet
+	}
+	switch {
+	case r == '\u30fc' || unicode.Is(unicode.Katakana, r):
+		return wbKatakana
+	case unicode.Is(unicode.Hiragana, r):
+		return wbHiragana
+	case unicode.Is(unicode.Han, r):
+		return wbIdeographic
+	case unicode.IsLetter(r):
+		return wbLetter
+	case unicode.IsDigit(r):
+		return wbNumeric
+	case unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me, unicode.Cf):
+		return wbExtend
+	}
+	return wbOther
+}
+
+// matchURLEmail returns the length and type of a URL or email
+// address at the start of content, or 0 if there is none
+func matchURLEmail(content string) (int, string) {
+	if loc := urlRegexp.FindStringIndex(content); loc != nil {
+		// trailing punctuation is more likely part of the sentence
+		url := strings.TrimRight(content[:loc[1]], ".,;:!?")
+		return len(url), TypeURL
+	}
+	if loc := emailRegexp.FindStringIndex(content); loc != nil {
+		return loc[1], TypeEmail
+	}
+	return 0, ""
+}
@@ -5,10 +5,77 @@ import (
 	"testing"
 )
 
-// test tokenizer splits by whitespace
+// test default tokenizer is the standard tokenizer, splitting numbers
+// on whitespace with their positions and offsets
 func TestTokenizer_Tokenize(t *testing.T) {
 	tokenizer := NewTokenizer()
 	have := tokenizer.Tokenize("1 2 3\n4\t5")
-	want := []string{"1", "2", "3", "4", "5"}
+	want := []Token{
+		{Term: "1", Position: 0, Start: 0, End: 1, Type: TypeNum},
+		{Term: "2", Position: 1, Start: 2, End: 3, Type: TypeNum},
+		{Term: "3", Position: 2, Start: 4, End: 5, Type: TypeNum},
+		{Term: "4", Position: 3, Start: 6, End: 7, Type: TypeNum},
+		{Term: "5", Position: 4, Start: 8, End: 9, Type: TypeNum},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestWhitespaceTokenizer_Tokenize(t *testing.T) {
+	have := WhitespaceTokenizer{}.Tokenize("a, b!\nc")
+	want := []Token{
+		{Term: "a,", Position: 0, Start: 0, End: 2, Type: TypeWord},
+		{Term: "b!", Position: 1, Start: 3, End: 5, Type: TypeWord},
+		{Term: "c", Position: 2, Start: 6, End: 7, Type: TypeWord},
+	}
+	assert.Equal(t, want, have)
+}
+
+func TestStandardTokenizer_Tokenize(t *testing.T) {
+	terms := func(tokens []Token) []string {
+		var r []string
+		for _, t := range tokens {
+			r = append(r, t.Term)
+		}
+		return r
+	}
+	for _, tcase := range []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"punctuation is removed", "a full-text search engine!", []string{"a", "full", "text", "search", "engine"}},
+		{"apostrophes within words", "can't stop 'quoted'", []string{"can't", "stop", "quoted"}},
+		{"decimal and grouped numbers", "3.14 and 1,000.", []string{"3.14", "and", "1,000"}},
+		{"letters and digits", "win10 x_y _", []string{"win10", "x_y"}},
+		{"dots between letters", "e.g. U.S.A.", []string{"e.g", "U.S.A"}},
+		{"combining marks", "café naïve", []string{"café", "naïve"}},
+		{"ideographs are split", "中文abc", []string{"中", "文", "abc"}},
+		{"katakana runs", "コーヒー", []string{"コーヒー"}},
+		{"email is split", "a.b@c.com", []string{"a.b", "c.com"}},
+		{"empty", " ,. ", nil},
+	} {
+		assert.Equal(t, tcase.want, terms(StandardTokenizer{}.Tokenize(tcase.content)), tcase.name)
+	}
+
+	// token types
+	have := StandardTokenizer{}.Tokenize("a 1 中 ひ カ 한국")
+	want := []Token{
+		{Term: "a", Position: 0, Start: 0, End: 1, Type: TypeAlphaNum},
+		{Term: "1", Position: 1, Start: 2, End: 3, Type: TypeNum},
+		{Term: "中", Position: 2, Start: 4, End: 7, Type: TypeIdeographic},
+		{Term: "ひ", Position: 3, Start: 8, End: 11, Type: TypeHiragana},
+		{Term: "カ", Position: 4, Start: 12, End: 15, Type: TypeKatakana},
+		{Term: "한국", Position: 5, Start: 16, End: 22, Type: TypeHangul},
+	}
+	assert.Equal(t, want, have)
+
+	// urls and emails
+	have = StandardTokenizer{URLEmail: true}.Tokenize("mail a.b@c.com, see https://x.org/a?b=c.")
+	want = []Token{
+		{Term: "mail", Position: 0, Start: 0, End: 4, Type: TypeAlphaNum},
+		{Term: "a.b@c.com", Position: 1, Start: 5, End: 14, Type: TypeEmail},
+		{Term: "see", Position: 2, Start: 16, End: 19, Type: TypeAlphaNum},
+		{Term: "https://x.org/a?b=c", Position: 3, Start: 20, End: 39, Type: TypeURL},
+	}
 	assert.Equal(t, want, have)
 }
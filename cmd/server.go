@@ -1,10 +1,14 @@
 package main
 
 import (
+	"flag"
+	"github.com/richardjennings/invertedindex/analyser"
 	"github.com/richardjennings/invertedindex/server"
 )
 
 func main() {
+	flag.StringVar(&analyser.ConfigDir, "config", analyser.ConfigDir, "directory of files named in analysis settings")
+	flag.Parse()
 	s := server.NewServer()
 	err := s.Serve()
 	if err != nil {
@@ -20,7 +20,14 @@ func main() {
 			"type": index.Keyword,
 		},
 		"lastname": {
-			"type": index.Keyword,
+			"type":   index.Keyword,
+			"fields": "phonetic",
+		},
+		// the lastname indexed by sound to match misspellings
+		"lastname.phonetic": {
+			"type":      index.Text,
+			"tokenizer": "standard",
+			"filter":    "phonetic",
 		},
 		"technology": {
 			"type": index.Keyword,
@@ -57,5 +64,15 @@ func main() {
 		fmt.Println("matched: ", uri)
 	}
 
+	r, err = e.Search("programmers", &inverted.SearchRequest{Query: &inverted.Query{Leaf: &inverted.MatchQuery{Term: "Strostrup", Field: "lastname.phonetic"}}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, d := range r["hits"] {
+		uri, _ := idx.Doc(d)
+		fmt.Println("sounds like: ", uri)
+	}
+
 
 }
@@ -3,6 +3,11 @@ package index
 import (
 	"errors"
 	"fmt"
+	"github.com/richardjennings/invertedindex/analyser"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
 )
 
 // The Document Index
@@ -10,6 +15,12 @@ type Index struct {
 	DocumentIndex map[string]int
 	Documents     []Document
 	Idxs          map[string]Idx
+	Nested        map[string]*NestedIndex
+	Analysis      analyser.Analysis
+	// MultiFields are the names of the multi-fields of each field, which
+	// index the content of the field differently, such as with another
+	// analyser
+	MultiFields map[string][]string
 }
 
 type Stats struct {
@@ -31,18 +42,33 @@ type Result interface {
 
 type Schema map[string]map[string]string
 
+// Index Settings
+type Settings struct {
+	// named analysis components that may be referenced by fields
+	Analysis analyser.Analysis `json:"analysis"`
+}
+
 // Field Index Interface
 type Idx interface {
 	Stats() IdxStats
 	Index(docId int, content interface{}) error
 }
 
+// arrayValue is implemented by field indexes of values which may be given
+// as an array, such as a [lon, lat] location, reporting whether an array
+// is a single value rather than an array of values
+type arrayValue interface {
+	isArrayValue(a []interface{}) bool
+}
+
 // Query Interfaces
 type Match interface {
 	MatchQuery(query string) (TermFreqResult, error)
+	MatchQueryWithAnalyser(query string, a *analyser.FullTextAnalyser) (TermFreqResult, error)
 }
 type Phrase interface {
 	PhraseQuery(query string) (PostingResult, error)
+	PhraseQueryWithAnalyser(query string, a *analyser.FullTextAnalyser) (PostingResult, error)
 }
 type Term interface {
 	TermQuery(query string) (KeywordResult, error)
@@ -50,6 +76,16 @@ type Term interface {
 type Terms interface {
 	TermsQuery(query []string) (KeywordResult, error)
 }
+type Range interface {
+	RangeQuery(b Bounds) (NumericResult, error)
+}
+type GeoBoundingBox interface {
+	GeoBoundingBoxQuery(topLeft LatLon, bottomRight LatLon) (NumericResult, error)
+}
+type GeoDistance interface {
+	GeoDistanceQuery(origin LatLon, distance float64) (NumericResult, error)
+	GeoDistances(origin LatLon) map[int]float64
+}
 
 // Aggregation Interfaces
 type TermsAggregation interface {
@@ -57,9 +93,25 @@ type TermsAggregation interface {
 }
 
 func NewIndex(cf map[string]map[string]string) (*Index, error) {
+	return NewIndexWithSettings(cf, Settings{})
+}
+
+func NewIndexWithSettings(cf map[string]map[string]string, settings Settings) (*Index, error) {
 	cidx := Index{}
 	cidx.Idxs = make(map[string]Idx)
+	cidx.Nested = make(map[string]*NestedIndex)
+	cidx.MultiFields = make(map[string][]string)
 	cidx.DocumentIndex = make(map[string]int)
+	cidx.Analysis = settings.Analysis
+
+	if err := cidx.Analysis.Validate(); err != nil {
+		return nil, err
+	}
+
+	cf, err := cidx.newNestedIndexes(cf, settings)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(cf) > 0 {
 		// create the mapping specified
@@ -67,23 +119,95 @@ func NewIndex(cf map[string]map[string]string) (*Index, error) {
 			typ, hasType := v["type"]
 			if hasType {
 				switch typ {
-				case "text":
+				case Text:
+					a, s, err := newTextAnalysers(v, cidx.Analysis)
+					if err != nil {
+						return nil, err
+					}
+					idx := NewTextIndex()
+					idx.Analyser = *a
+					idx.SearchAnalyser = s
+					switch v["index_options"] {
+					case "", IndexOptionsPositions:
+					case IndexOptionsOffsets:
+						idx.StoreOffsets = true
+					default:
+						return nil, fmt.Errorf("unknown index_options %s", v["index_options"])
+					}
+					if m, ok := v["max_token_count"]; ok {
+						idx.MaxTokens, err = strconv.Atoi(m)
+						if err != nil || idx.MaxTokens < 1 {
+							return nil, fmt.Errorf("invalid max_token_count %s", m)
+						}
+					}
+					if g, ok := v["position_increment_gap"]; ok {
+						idx.PositionIncrementGap, err = strconv.Atoi(g)
+						if err != nil || idx.PositionIncrementGap < 0 {
+							return nil, fmt.Errorf("invalid position_increment_gap %s", g)
+						}
+					}
 					// currently error cannot occur because field duplication case
 					// is prevented by the map key in this function
-					_, _ = cidx.newFieldIndex(field, NewTextIndex())
-				case "keyword":
-					_, _ = cidx.newFieldIndex(field, NewKeywordIndex())
+					_, _ = cidx.newFieldIndex(field, idx)
+				case Keyword:
+					n, err := newKeywordAnalyser(v, cidx.Analysis)
+					if err != nil {
+						return nil, err
+					}
+					idx := NewKeywordIndex()
+					idx.Analyser = *n
+					_, _ = cidx.newFieldIndex(field, idx)
+				case Long, Integer, Double:
+					idx, err := NewNumericIndex(typ)
+					if err != nil {
+						return nil, err
+					}
+					_, _ = cidx.newFieldIndex(field, idx)
+				case Boolean:
+					_, _ = cidx.newFieldIndex(field, NewBooleanIndex())
+				case IP:
+					_, _ = cidx.newFieldIndex(field, NewIPIndex())
+				case GeoPoint:
+					_, _ = cidx.newFieldIndex(field, NewGeoPointIndex())
+				case Object:
+					// objects are flattened into the fields of their properties
+				case Date:
+					idx, err := NewDateIndex(v["format"])
+					if err != nil {
+						return nil, err
+					}
+					_, _ = cidx.newFieldIndex(field, idx)
 				default:
 					return nil, errors.New("unknown field type")
 				}
 			} else {
 				return nil, errors.New("missing type")
 			}
+			if err := cidx.newMultiFields(field, v["fields"], cf); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return &cidx, nil
 }
 
+// newMultiFields records the multi-fields of field listed by the fields
+// key of its mapping, each mapped as field.name
+func (ci *Index) newMultiFields(field string, names string, cf map[string]map[string]string) error {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		sub := field + "." + name
+		if _, ok := cf[sub]; !ok {
+			return fmt.Errorf("missing mapping for multi-field %s", sub)
+		}
+		ci.MultiFields[field] = append(ci.MultiFields[field], sub)
+	}
+	return nil
+}
+
 func (ci *Index) Stats() *Stats {
 	stats := Stats{}
 	stats.DocumentCount = len(ci.Documents)
@@ -91,6 +215,11 @@ func (ci *Index) Stats() *Stats {
 	for name, idx := range ci.Idxs {
 		stats.Fields[name] = idx.Stats()
 	}
+	for _, nested := range ci.Nested {
+		for name, s := range nested.Stats().Fields {
+			stats.Fields[name] = s
+		}
+	}
 	return &stats
 }
 
@@ -111,6 +240,19 @@ func (ci *Index) GetFieldIdx(field string) (Idx, error) {
 	return nil, errors.New("field not found")
 }
 
+// FieldAnalyser returns the analyser used to index a text field
+func (ci *Index) FieldAnalyser(field string) (*analyser.FullTextAnalyser, error) {
+	idx, err := ci.GetFieldIdx(field)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := idx.(*IndexText)
+	if !ok {
+		return nil, errors.New("field does not support analysis")
+	}
+	return &t.Analyser, nil
+}
+
 func (ci *Index) Index(uri string, content map[string]interface{}) error {
 	_, ok := ci.DocumentIndex[uri]
 	if ok {
@@ -122,19 +264,82 @@ func (ci *Index) Index(uri string, content map[string]interface{}) error {
 	docId := len(ci.Documents) - 1
 	ci.DocumentIndex[uri] = docId
 
-	for field, txt := range content {
+	return ci.indexFields(docId, uri, "", content)
+}
+
+// indexFields indexes the content of a document, whose fields are named
+// by their path below prefix
+func (ci *Index) indexFields(docId int, uri string, prefix string, content map[string]interface{}) error {
+	fields := make(map[string][]interface{})
+	ci.flatten(prefix, content, fields)
+	for field, values := range fields {
+		av, _ := ci.Idxs[field].(arrayValue)
+		txt := fieldContent(values, av)
+		if nested, ok := ci.Nested[field]; ok {
+			if err := nested.index(docId, uri, field, txt); err != nil {
+				return err
+			}
+			continue
+		}
 		idx, ok := ci.Idxs[field]
 		if !ok {
 			return errors.New("field not found")
 		}
-		err := idx.Index(docId, txt)
-		if err != nil {
-			return err
+		idxs := []Idx{idx}
+		for _, sub := range ci.MultiFields[field] {
+			idxs = append(idxs, ci.Idxs[sub])
+		}
+		if r, ok := txt.(io.Reader); ok && len(idxs) > 1 {
+			if err := indexReader(docId, r, idxs); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, idx := range idxs {
+			if err := idx.Index(docId, txt); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+// indexReader indexes content given as a reader in each of idxs as it is
+// read, so that content indexed by multi-fields is not held in memory
+func indexReader(docId int, r io.Reader, idxs []Idx) error {
+	writers := make([]io.Writer, len(idxs)-1)
+	pipes := make([]*io.PipeWriter, len(idxs)-1)
+	errs := make(chan error, len(idxs)-1)
+	for i, idx := range idxs[1:] {
+		pr, pw := io.Pipe()
+		writers[i], pipes[i] = pw, pw
+		go func(idx Idx) {
+			// the pipe is wrapped so that it is not read concurrently
+			// when formatted in the error of an index rejecting it
+			err := idx.Index(docId, struct{ io.Reader }{pr})
+			// the rest of the content is read should the index stop
+			// early, so that the other indexes are not blocked
+			_, _ = io.Copy(ioutil.Discard, pr)
+			errs <- err
+		}(idx)
+	}
+	w := io.MultiWriter(writers...)
+	err := idxs[0].Index(docId, io.TeeReader(r, w))
+	if err == nil {
+		// content not read by the field, such as after max_token_count
+		_, err = io.Copy(w, r)
+	}
+	for _, pw := range pipes {
+		_ = pw.CloseWithError(err)
+	}
+	for range pipes {
+		if e := <-errs; err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
 func (ci Index) Doc(id int) (string, error) {
 	if id < len(ci.Documents) {
 		return ci.Documents[id].URI, nil
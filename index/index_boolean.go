@@ -0,0 +1,67 @@
+package index
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const Boolean = "boolean"
+
+// IndexBoolean indexes the values true and false as the keywords
+// "true" and "false"
+type IndexBoolean struct {
+	IndexKeyword
+}
+
+// NewBooleanIndex creates an index of boolean values
+func NewBooleanIndex() *IndexBoolean {
+	return &IndexBoolean{IndexKeyword: *NewKeywordIndex()}
+}
+
+// Index adds the values of a document, given as a JSON boolean, the
+// string "true" or "false" or an array of these
+func (idx *IndexBoolean) Index(docId int, content interface{}) error {
+	values, ok := content.([]interface{})
+	if !ok {
+		values = []interface{}{content}
+	}
+	for _, v := range values {
+		b, err := parseBool(v)
+		if err != nil {
+			return err
+		}
+		if err := idx.IndexKeyword.Index(docId, strconv.FormatBool(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TermQuery finds documents with the value query, true or false
+func (idx *IndexBoolean) TermQuery(query string) (KeywordResult, error) {
+	b, err := parseBool(query)
+	if err != nil {
+		return nil, err
+	}
+	return idx.IndexKeyword.TermQuery(strconv.FormatBool(b))
+}
+
+func (idx *IndexBoolean) TermsQuery(query []string) (KeywordResult, error) {
+	return termsQuery(query, idx.TermQuery)
+}
+
+// parseBool parses a boolean given as a JSON boolean or a string
+func parseBool(v interface{}) (bool, error) {
+	switch v.(type) {
+	case bool:
+		return v.(bool), nil
+	case string:
+		switch v.(string) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("failed to parse boolean %v", v)
+}
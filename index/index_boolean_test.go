@@ -0,0 +1,33 @@
+package index
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndexBoolean(t *testing.T) {
+	idx := NewBooleanIndex()
+	for docId, v := range []interface{}{true, false, "true", []interface{}{true, "false"}} {
+		err := idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, IdxStats{TermCount: 2}, idx.Stats())
+
+	r, err := idx.TermQuery("true")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 2: 1, 3: 1}, r)
+	r, err = idx.TermQuery("false")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1, 3: 1}, r)
+	r, err = idx.TermsQuery([]string{"true", "false"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 1: 1, 2: 1, 3: 1}, r)
+
+	_, err = idx.TermQuery("yes")
+	assert.Equal(t, errors.New("failed to parse boolean yes"), err)
+	_, err = idx.TermsQuery([]string{"true", "1"})
+	assert.Equal(t, errors.New("failed to parse boolean 1"), err)
+	assert.Equal(t, errors.New("failed to parse boolean True"), idx.Index(4, "True"))
+	assert.Equal(t, errors.New("failed to parse boolean 1"), idx.Index(4, float64(1)))
+}
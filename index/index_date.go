@@ -0,0 +1,369 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Date = "date"
+
+	// DefaultDateFormat accepts ISO-8601 dates and milliseconds since the epoch
+	DefaultDateFormat = "strict_date_optional_time||epoch_millis"
+)
+
+// IndexDate indexes dates as points of milliseconds since the epoch in UTC.
+// Values are parsed with the first of the formats separated by || that
+// matches, either a built-in format or a pattern such as yyyy/MM/dd HH:mm
+type IndexDate struct {
+	IndexNumeric
+	Format string
+	// Now is the time of now in date math, the current time when nil
+	Now     func() time.Time
+	parsers []dateParser
+}
+
+type dateParser func(s string) (time.Time, error)
+
+// isoLayouts are the layouts of strict_date_optional_time from the most
+// precise, fractions of seconds are accepted after seconds
+var isoLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02T15Z07:00",
+	"2006-01-02T15",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// dateFormats are the built-in formats
+var dateFormats = map[string]dateParser{
+	"strict_date_optional_time": layoutParser(isoLayouts...),
+	"date_optional_time":        layoutParser(isoLayouts...),
+	"strict_date_time":          layoutParser("2006-01-02T15:04:05Z07:00"),
+	"date_time":                 layoutParser("2006-01-02T15:04:05Z07:00"),
+	"strict_date":               layoutParser("2006-01-02"),
+	"date":                      layoutParser("2006-01-02"),
+	"basic_date":                layoutParser("20060102"),
+	"epoch_millis":              epochParser(time.Millisecond),
+	"epoch_second":              epochParser(time.Second),
+}
+
+// NewDateIndex creates an index of dates parsed with format, by default
+// DefaultDateFormat
+func NewDateIndex(format string) (*IndexDate, error) {
+	if format == "" {
+		format = DefaultDateFormat
+	}
+	parsers, err := newDateParsers(format)
+	if err != nil {
+		return nil, err
+	}
+	return &IndexDate{IndexNumeric: IndexNumeric{Type: Date}, Format: format, parsers: parsers}, nil
+}
+
+// Index adds the dates of a document, given as a string or number parsed
+// with the format of the field, a time.Time or an array of these
+func (idx *IndexDate) Index(docId int, content interface{}) error {
+	values, ok := content.([]interface{})
+	if !ok {
+		values = []interface{}{content}
+	}
+	for _, v := range values {
+		t, err := parseDate(v, idx.parsers)
+		if err != nil {
+			return err
+		}
+		idx.insert(docId, longKey(millis(t)))
+	}
+	return nil
+}
+
+// RangeQuery finds documents with dates within bounds, which may use date
+// math such as now-7d/d. Rounding includes the whole unit rounded to in
+// lte and excludes it in gt
+func (idx *IndexDate) RangeQuery(b Bounds) (NumericResult, error) {
+	parsers := idx.parsers
+	if b.Format != "" {
+		var err error
+		if parsers, err = newDateParsers(b.Format); err != nil {
+			return nil, err
+		}
+	}
+	bounds := Bounds{}
+	for _, bound := range []struct {
+		v       interface{}
+		roundUp bool
+		set     *interface{}
+	}{{b.Gt, true, &bounds.Gt}, {b.Gte, false, &bounds.Gte}, {b.Lt, false, &bounds.Lt}, {b.Lte, true, &bounds.Lte}} {
+		if bound.v == nil {
+			continue
+		}
+		t, err := idx.dateMath(bound.v, bound.roundUp, parsers)
+		if err != nil {
+			return nil, err
+		}
+		*bound.set = millis(t)
+	}
+	lo, hi, err := longRange(bounds)
+	if err != nil {
+		return nil, err
+	}
+	return idx.keyRange(lo, hi), nil
+}
+
+// TermQuery finds documents with the date query, to the millisecond
+func (idx *IndexDate) TermQuery(query string) (KeywordResult, error) {
+	t, err := idx.dateMath(query, false, idx.parsers)
+	if err != nil {
+		return nil, err
+	}
+	key := longKey(millis(t))
+	return KeywordResult(idx.keyRange(key, key)), nil
+}
+
+func (idx *IndexDate) TermsQuery(query []string) (KeywordResult, error) {
+	return termsQuery(query, idx.TermQuery)
+}
+
+// dateMath parses a date optionally followed by operations, where the date
+// is either now or a date followed by ||, such as 2020-01-01||+1M/d.
+// Operations add (+) or subtract (-) a number of units or round (/) down
+// to a unit, or up to its last millisecond when roundUp is set. Units
+// are y, M, w, d, h, H, m and s
+func (idx *IndexDate) dateMath(v interface{}, roundUp bool, parsers []dateParser) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return parseDate(v, parsers)
+	}
+	var t time.Time
+	var err error
+	var expr string
+	switch {
+	case strings.HasPrefix(s, "now"):
+		t = time.Now()
+		if idx.Now != nil {
+			t = idx.Now()
+		}
+		t = t.UTC()
+		expr = s[3:]
+	case strings.Contains(s, "||"):
+		i := strings.Index(s, "||")
+		if t, err = parseDate(s[:i], parsers); err != nil {
+			return t, err
+		}
+		expr = s[i+2:]
+	default:
+		return parseDate(s, parsers)
+	}
+	for len(expr) > 0 {
+		op := expr[0]
+		expr = expr[1:]
+		n := 1
+		if op == '+' || op == '-' {
+			i := 0
+			for i < len(expr) && expr[i] >= '0' && expr[i] <= '9' {
+				i++
+			}
+			if i > 0 {
+				n, _ = strconv.Atoi(expr[:i])
+			}
+			expr = expr[i:]
+			if op == '-' {
+				n = -n
+			}
+		} else if op != '/' {
+			return t, fmt.Errorf("invalid date math %s", s)
+		}
+		if len(expr) == 0 || !strings.Contains("yMwdhHms", expr[:1]) {
+			return t, fmt.Errorf("invalid date math %s", s)
+		}
+		unit := expr[0]
+		expr = expr[1:]
+		switch {
+		case op != '/':
+			t = addDate(t, n, unit)
+		case roundUp:
+			t = addDate(roundDate(t, unit), 1, unit).Add(-time.Millisecond)
+		default:
+			t = roundDate(t, unit)
+		}
+	}
+	return t, nil
+}
+
+// addDate adds n units to t
+func addDate(t time.Time, n int, unit byte) time.Time {
+	switch unit {
+	case 'y':
+		return t.AddDate(n, 0, 0)
+	case 'M':
+		return t.AddDate(0, n, 0)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'h', 'H':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	default:
+		return t.Add(time.Duration(n) * time.Second)
+	}
+}
+
+// roundDate rounds t down to the start of a unit, weeks starting on Monday
+func roundDate(t time.Time, unit byte) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch unit {
+	case 'y':
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	case 'M':
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case 'w':
+		return day.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case 'd':
+		return day
+	case 'h', 'H':
+		return t.Truncate(time.Hour)
+	case 'm':
+		return t.Truncate(time.Minute)
+	default:
+		return t.Truncate(time.Second)
+	}
+}
+
+// millis returns the milliseconds since the epoch of t
+func millis(t time.Time) int64 {
+	return t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+}
+
+// parseDate parses a date given as a string or a JSON number with the
+// first of parsers that succeeds
+func parseDate(v interface{}, parsers []dateParser) (time.Time, error) {
+	var s string
+	switch v.(type) {
+	case time.Time:
+		return v.(time.Time).UTC(), nil
+	case string:
+		s = v.(string)
+	case json.Number:
+		s = v.(json.Number).String()
+	case float64:
+		s = strconv.FormatFloat(v.(float64), 'f', -1, 64)
+	case int:
+		s = strconv.Itoa(v.(int))
+	case int64:
+		s = strconv.FormatInt(v.(int64), 10)
+	default:
+		return time.Time{}, fmt.Errorf("expected date, got %v", v)
+	}
+	for _, p := range parsers {
+		if t, err := p(s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse date %s", s)
+}
+
+// newDateParsers returns the parsers of the formats separated by ||
+func newDateParsers(format string) ([]dateParser, error) {
+	var parsers []dateParser
+	for _, f := range strings.Split(format, "||") {
+		if p, ok := dateFormats[f]; ok {
+			parsers = append(parsers, p)
+			continue
+		}
+		layout, err := dateLayout(f)
+		if err != nil {
+			return nil, err
+		}
+		parsers = append(parsers, layoutParser(layout))
+	}
+	return parsers, nil
+}
+
+// layoutParser parses with the first matching layout, in UTC unless the
+// layout has a zone
+func layoutParser(layouts ...string) dateParser {
+	return func(s string) (time.Time, error) {
+		var err error
+		for _, layout := range layouts {
+			var t time.Time
+			if t, err = time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		return time.Time{}, err
+	}
+}
+
+// epochParser parses an integer number of units since the epoch
+func epochParser(unit time.Duration) dateParser {
+	return func(s string) (time.Time, error) {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		perSecond := int64(time.Second / unit)
+		return time.Unix(n/perSecond, n%perSecond*int64(unit)).UTC(), nil
+	}
+}
+
+// dateLayouts are the Go layouts of runs of pattern letters
+var dateLayouts = map[string]string{
+	"yyyy": "2006", "yy": "06", "y": "2006",
+	"MMMM": "January", "MMM": "Jan", "MM": "01", "M": "1",
+	"dd": "02", "d": "2",
+	"EEEE": "Monday", "EEE": "Mon", "E": "Mon",
+	"HH": "15", "H": "15",
+	"hh": "03", "h": "3",
+	"mm": "04", "m": "4",
+	"ss": "05", "s": "5",
+	"a": "PM",
+	"Z": "-0700", "ZZ": "-07:00",
+	"X": "Z07", "XX": "Z0700", "XXX": "Z07:00",
+	"z": "MST",
+}
+
+// dateLayout converts a date pattern such as yyyy-MM-dd'T'HH:mm:ss.SSSZ to a
+// Go layout. Text in quotes is literal
+func dateLayout(pattern string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '\'':
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unknown date format %s", pattern)
+			}
+			b.WriteString(pattern[i+1 : i+1+end])
+			i += end + 2
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(pattern) && pattern[j] == c {
+				j++
+			}
+			run := pattern[i:j]
+			if c == 'S' {
+				// fractions of seconds follow a literal . or ,
+				run = strings.Repeat("0", len(run))
+			} else if run = dateLayouts[run]; run == "" {
+				return "", fmt.Errorf("unknown date format %s", pattern)
+			}
+			b.WriteString(run)
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), nil
+}
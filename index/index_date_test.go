@@ -0,0 +1,120 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIndexDate_Index(t *testing.T) {
+	idx, err := NewDateIndex("")
+	assert.Nil(t, err)
+	for docId, v := range []interface{}{
+		"2020-01-15",
+		"2020-01-15T10:30:00Z",
+		"2020-01-15T10:30:00.250+01:00",
+		json.Number("1579084200000"),
+		"1579084200000",
+		[]interface{}{"2020", "2020-02"},
+		time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC),
+	} {
+		err = idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	for _, tcase := range []struct {
+		query string
+		want  KeywordResult
+	}{
+		{"2020-01-15", KeywordResult{0: 1, 6: 1}},
+		{"2020-01-15T10:30:00Z", KeywordResult{1: 1, 3: 1, 4: 1}},
+		{"2020-01-15T09:30:00.250Z", KeywordResult{2: 1}},
+		{"2020-01-01", KeywordResult{5: 1}},
+		{"2020-02-01T00:00:00Z", KeywordResult{5: 1}},
+	} {
+		r, err := idx.TermQuery(tcase.query)
+		assert.Nil(t, err, tcase.query)
+		assert.Equal(t, tcase.want, r, tcase.query)
+	}
+	r, err := idx.TermsQuery([]string{"2020-01-01", "2020-01-15"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 5: 1, 6: 1}, r)
+	assert.Equal(t, IdxStats{TermCount: 5}, idx.Stats())
+
+	// invalid values
+	assert.Equal(t, errors.New("failed to parse date 15/01/2020"), idx.Index(7, "15/01/2020"))
+	assert.Equal(t, errors.New("expected date, got true"), idx.Index(7, true))
+}
+
+func TestIndexDate_Formats(t *testing.T) {
+	idx, err := NewDateIndex("yyyy/MM/dd HH:mm:ss.SSS||dd MMM yyyy||epoch_second")
+	assert.Nil(t, err)
+	for docId, v := range []interface{}{"2020/01/15 10:30:00.250", "15 Jan 2020", float64(1579084200)} {
+		err = idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	r, err := idx.RangeQuery(Bounds{Gte: "2020-01-15T10:30:00Z", Format: "strict_date_optional_time"})
+	assert.Nil(t, err)
+	assert.Equal(t, NumericResult{0: 1, 2: 1}, r)
+	r, err = idx.RangeQuery(Bounds{Lt: "2020/01/15 10:30:00.250"})
+	assert.Nil(t, err)
+	assert.Equal(t, NumericResult{1: 1, 2: 1}, r)
+
+	_, err = NewDateIndex("yyyy-MM-dd'T")
+	assert.Equal(t, errors.New("unknown date format yyyy-MM-dd'T"), err)
+	_, err = NewDateIndex("yyyy-MM-dd||qq")
+	assert.Equal(t, errors.New("unknown date format qq"), err)
+	_, err = idx.RangeQuery(Bounds{Gt: "2020", Format: "Q"})
+	assert.Equal(t, errors.New("unknown date format Q"), err)
+}
+
+func TestIndexDate_RangeQuery(t *testing.T) {
+	idx, err := NewDateIndex("")
+	assert.Nil(t, err)
+	idx.Now = func() time.Time {
+		return time.Date(2020, 3, 18, 15, 45, 0, 0, time.UTC)
+	}
+	for docId, v := range []interface{}{
+		"2020-03-18T09:00:00Z",
+		"2020-03-11",
+		"2020-03-10T23:59:59.999Z",
+		"2020-03-16",
+		"2020-02-29",
+		"2019-12-31T23:59:59Z",
+	} {
+		err = idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	for _, tcase := range []struct {
+		name   string
+		bounds Bounds
+		want   NumericResult
+	}{
+		{"all", Bounds{}, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 1, 5: 1}},
+		{"last 7 days", Bounds{Gte: "now-7d/d"}, NumericResult{0: 1, 1: 1, 3: 1}},
+		{"before today", Bounds{Lt: "now/d"}, NumericResult{1: 1, 2: 1, 3: 1, 4: 1, 5: 1}},
+		{"lte rounds up", Bounds{Lte: "now-2d/d"}, NumericResult{1: 1, 2: 1, 3: 1, 4: 1, 5: 1}},
+		{"gt rounds up", Bounds{Gt: "now-7d/d"}, NumericResult{0: 1, 3: 1}},
+		{"this week", Bounds{Gte: "now/w"}, NumericResult{0: 1, 3: 1}},
+		{"this year", Bounds{Gte: "now/y"}, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 1}},
+		{"anchor", Bounds{Gte: "2020-02-01||/M", Lte: "2020-02-01||/M"}, NumericResult{4: 1}},
+		{"anchor add", Bounds{Gte: "2020-02-01||+1M", Lt: "2020-03-01||+10d"}, NumericResult{2: 1}},
+		{"hours", Bounds{Gt: "now-7h"}, NumericResult{0: 1}},
+		{"epoch millis", Bounds{Lt: float64(1577836800000)}, NumericResult{5: 1}},
+		{"dates", Bounds{Gte: "2020-03-01", Lte: "2020-03-11"}, NumericResult{1: 1, 2: 1}},
+	} {
+		r, err := idx.RangeQuery(tcase.bounds)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+
+	for _, bound := range []string{"now-7x", "now*2d", "now-", "2020-01-01||+1"} {
+		_, err = idx.RangeQuery(Bounds{Gt: bound})
+		assert.Equal(t, errors.New("invalid date math "+bound), err, bound)
+	}
+	_, err = idx.RangeQuery(Bounds{Gt: "yesterday"})
+	assert.Equal(t, errors.New("failed to parse date yesterday"), err)
+	_, err = idx.RangeQuery(Bounds{Gt: "x||/d"})
+	assert.Equal(t, errors.New("failed to parse date x"), err)
+}
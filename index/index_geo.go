@@ -0,0 +1,351 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const GeoPoint = "geo_point"
+
+// geoBits is the number of bits of each of latitude and longitude in keys
+const geoBits = 26
+
+// maxGeoCells is the most cells searched for the points within a box
+const maxGeoCells = 64
+
+// earthRadius is the mean radius of the Earth in metres
+const earthRadius = 6371008.7714
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// distanceUnits are the lengths in metres of the units of distances
+var distanceUnits = map[string]float64{
+	"mm":  0.001,
+	"cm":  0.01,
+	"m":   1,
+	"km":  1000,
+	"in":  0.0254,
+	"ft":  0.3048,
+	"yd":  0.9144,
+	"mi":  1609.344,
+	"nmi": 1852,
+	"NM":  1852,
+}
+
+// IndexGeoPoint indexes locations as points ordered by a key interleaving
+// the bits of longitude and latitude, as a geohash does, so that the points
+// within a cell share a prefix and are found by a binary search. A box is
+// searched by the cells covering it
+type IndexGeoPoint struct {
+	Points Points
+	// Locations are the locations of points by Ref
+	Locations []LatLon
+}
+
+// LatLon is a location in degrees
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// NewGeoPointIndex creates an index of locations
+func NewGeoPointIndex() *IndexGeoPoint {
+	return &IndexGeoPoint{}
+}
+
+func (idx *IndexGeoPoint) Stats() (stats IdxStats) {
+	points := idx.Points.All()
+	for i, p := range points {
+		if i == 0 || idx.Locations[p.Ref] != idx.Locations[points[i-1].Ref] {
+			stats.TermCount++
+		}
+	}
+	return stats
+}
+
+// Index adds the locations of a document, each given as an object with lat
+// and lon, a "lat,lon" string, a geohash, an array of [lon, lat] or a LatLon.
+// An array of locations adds each
+func (idx *IndexGeoPoint) Index(docId int, content interface{}) error {
+	values, ok := content.([]interface{})
+	if !ok || isLonLat(values) {
+		values = []interface{}{content}
+	}
+	for _, v := range values {
+		loc, err := ParseLatLon(v)
+		if err != nil {
+			return err
+		}
+		idx.Points.insert(docId, PointKey{Hi: geoKey(loc)}, len(idx.Locations))
+		idx.Locations = append(idx.Locations, loc)
+	}
+	return nil
+}
+
+// isArrayValue reports whether a is a location given as [lon, lat]
+func (idx *IndexGeoPoint) isArrayValue(a []interface{}) bool {
+	return isLonLat(a)
+}
+
+// GeoBoundingBoxQuery finds documents with locations within the box from
+// topLeft to bottomRight, which crosses the antimeridian when the left is
+// east of the right
+func (idx *IndexGeoPoint) GeoBoundingBoxQuery(topLeft LatLon, bottomRight LatLon) (NumericResult, error) {
+	if topLeft.Lat < bottomRight.Lat {
+		return nil, errors.New("top is below bottom")
+	}
+	result := NumericResult{}
+	within := func(LatLon) bool { return true }
+	if topLeft.Lon > bottomRight.Lon {
+		idx.boxQuery(bottomRight.Lat, topLeft.Lat, topLeft.Lon, 180, within, result)
+		idx.boxQuery(bottomRight.Lat, topLeft.Lat, -180, bottomRight.Lon, within, result)
+	} else {
+		idx.boxQuery(bottomRight.Lat, topLeft.Lat, topLeft.Lon, bottomRight.Lon, within, result)
+	}
+	return result, nil
+}
+
+// GeoDistanceQuery finds documents with locations within distance metres
+// of origin, searching the box around the circle
+func (idx *IndexGeoPoint) GeoDistanceQuery(origin LatLon, distance float64) (NumericResult, error) {
+	if distance < 0 || math.IsNaN(distance) {
+		return nil, fmt.Errorf("invalid distance %v", distance)
+	}
+	result := NumericResult{}
+	within := func(loc LatLon) bool {
+		return haversine(origin, loc) <= distance
+	}
+	angle := distance / earthRadius
+	minLat := origin.Lat - angle*180/math.Pi
+	maxLat := origin.Lat + angle*180/math.Pi
+	if minLat <= -90 || maxLat >= 90 || angle >= math.Pi/2 {
+		// the circle includes a pole so all longitudes
+		idx.boxQuery(math.Max(minLat, -90), math.Min(maxLat, 90), -180, 180, within, result)
+		return result, nil
+	}
+	dLon := math.Asin(math.Sin(angle)/math.Cos(origin.Lat*math.Pi/180)) * 180 / math.Pi
+	minLon, maxLon := origin.Lon-dLon, origin.Lon+dLon
+	switch {
+	case minLon < -180:
+		idx.boxQuery(minLat, maxLat, minLon+360, 180, within, result)
+		idx.boxQuery(minLat, maxLat, -180, maxLon, within, result)
+	case maxLon > 180:
+		idx.boxQuery(minLat, maxLat, minLon, 180, within, result)
+		idx.boxQuery(minLat, maxLat, -180, maxLon-360, within, result)
+	default:
+		idx.boxQuery(minLat, maxLat, minLon, maxLon, within, result)
+	}
+	return result, nil
+}
+
+// GeoDistances returns the distance in metres from origin to the nearest
+// location of each document
+func (idx *IndexGeoPoint) GeoDistances(origin LatLon) map[int]float64 {
+	distances := make(map[int]float64)
+	for _, p := range idx.Points.All() {
+		d := haversine(origin, idx.Locations[p.Ref])
+		if nearest, ok := distances[p.DocId]; !ok || d < nearest {
+			distances[p.DocId] = d
+		}
+	}
+	return distances
+}
+
+// boxQuery adds the documents with locations within the box and for which
+// within is true to result. The box is covered by at most maxGeoCells
+// cells of the largest size needed, each a range of keys
+func (idx *IndexGeoPoint) boxQuery(minLat, maxLat, minLon, maxLon float64, within func(LatLon) bool, result NumericResult) {
+	x0, x1 := quantize(minLon, 180), quantize(maxLon, 180)
+	y0, y1 := quantize(minLat, 90), quantize(maxLat, 90)
+	shift := uint(0)
+	for ; shift < geoBits; shift++ {
+		if (uint64(x1>>shift-x0>>shift)+1)*(uint64(y1>>shift-y0>>shift)+1) <= maxGeoCells {
+			break
+		}
+	}
+	for x := x0 >> shift; x <= x1>>shift; x++ {
+		for y := y0 >> shift; y <= y1>>shift; y++ {
+			lo := interleave(x, y) << (2 * shift)
+			hi := lo | (1<<(2*shift) - 1)
+			idx.Points.Range(PointKey{Hi: lo}, PointKey{Hi: hi}, func(p Point) {
+				loc := idx.Locations[p.Ref]
+				if loc.Lat >= minLat && loc.Lat <= maxLat && loc.Lon >= minLon && loc.Lon <= maxLon && within(loc) {
+					result[p.DocId]++
+				}
+			})
+		}
+	}
+}
+
+// geoKey interleaves the bits of longitude and latitude of loc
+func geoKey(loc LatLon) uint64 {
+	return interleave(quantize(loc.Lon, 180), quantize(loc.Lat, 90))
+}
+
+// quantize maps v from -max to max to an integer of geoBits bits
+func quantize(v float64, max float64) uint32 {
+	q := (v + max) / (2 * max) * (1 << geoBits)
+	if q >= 1<<geoBits {
+		return 1<<geoBits - 1
+	}
+	if q < 0 {
+		return 0
+	}
+	return uint32(q)
+}
+
+// interleave interleaves the bits of x and y from the most significant,
+// starting with x
+func interleave(x uint32, y uint32) uint64 {
+	var k uint64
+	for i := geoBits - 1; i >= 0; i-- {
+		k = k<<2 | uint64(x>>uint(i)&1)<<1 | uint64(y>>uint(i)&1)
+	}
+	return k
+}
+
+// haversine returns the great circle distance in metres between a and b
+func haversine(a LatLon, b LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// isLonLat reports whether values are the numbers of a [lon, lat] array
+func isLonLat(values []interface{}) bool {
+	if len(values) != 2 {
+		return false
+	}
+	for _, v := range values {
+		if _, ok := v.(string); ok {
+			return false
+		}
+		if _, err := parseDouble(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLatLon parses a location given as an object with lat and lon, a
+// "lat,lon" string, a geohash, an array of [lon, lat] or a LatLon
+func ParseLatLon(v interface{}) (LatLon, error) {
+	var loc LatLon
+	var err error
+	switch v.(type) {
+	case LatLon:
+		loc = v.(LatLon)
+	case map[string]interface{}:
+		m := v.(map[string]interface{})
+		lat, hasLat := m["lat"]
+		lon, hasLon := m["lon"]
+		if !hasLat || !hasLon || len(m) != 2 {
+			return loc, errors.New("expected lat and lon")
+		}
+		if loc.Lat, err = parseDouble(lat); err != nil {
+			return loc, err
+		}
+		if loc.Lon, err = parseDouble(lon); err != nil {
+			return loc, err
+		}
+	case []interface{}:
+		a := v.([]interface{})
+		if !isLonLat(a) {
+			return loc, fmt.Errorf("expected [lon, lat], got %v", v)
+		}
+		loc.Lon, _ = parseDouble(a[0])
+		loc.Lat, _ = parseDouble(a[1])
+	case string:
+		s := v.(string)
+		if !strings.Contains(s, ",") {
+			return decodeGeohash(s)
+		}
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 {
+			return loc, fmt.Errorf("invalid location %s", s)
+		}
+		if loc.Lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+			return loc, fmt.Errorf("invalid location %s", s)
+		}
+		if loc.Lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil {
+			return loc, fmt.Errorf("invalid location %s", s)
+		}
+	default:
+		return loc, fmt.Errorf("expected location, got %v", v)
+	}
+	if !(loc.Lat >= -90 && loc.Lat <= 90) {
+		return loc, fmt.Errorf("invalid latitude %v", loc.Lat)
+	}
+	if !(loc.Lon >= -180 && loc.Lon <= 180) {
+		return loc, fmt.Errorf("invalid longitude %v", loc.Lon)
+	}
+	return loc, nil
+}
+
+// decodeGeohash returns the centre of the cell of a geohash
+func decodeGeohash(hash string) (LatLon, error) {
+	if hash == "" {
+		return LatLon{}, fmt.Errorf("invalid geohash %s", hash)
+	}
+	minLat, maxLat, minLon, maxLon := -90.0, 90.0, -180.0, 180.0
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		n := strings.IndexRune(geohashAlphabet, c)
+		if n < 0 {
+			return LatLon{}, fmt.Errorf("invalid geohash %s", hash)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := n>>uint(bit)&1 == 1
+			if even {
+				mid := (minLon + maxLon) / 2
+				if set {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return LatLon{Lat: (minLat + maxLat) / 2, Lon: (minLon + maxLon) / 2}, nil
+}
+
+// ParseDistance parses a distance given as a number of metres or a string
+// with a unit such as 12km, returning metres
+func ParseDistance(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		d, err := parseDouble(v)
+		if err != nil {
+			return 0, err
+		}
+		return d, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	unit := 1.0
+	if i >= 0 {
+		if unit, ok = distanceUnits[strings.TrimSpace(s[i:])]; !ok {
+			return 0, fmt.Errorf("invalid distance %s", s)
+		}
+		s = s[:i]
+	}
+	d, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid distance %v", v)
+	}
+	return d * unit, nil
+}
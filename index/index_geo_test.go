@@ -0,0 +1,155 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	london    = LatLon{Lat: 51.5074, Lon: -0.1278}
+	paris     = LatLon{Lat: 48.8566, Lon: 2.3522}
+	amsterdam = LatLon{Lat: 52.3676, Lon: 4.9041}
+	newYork   = LatLon{Lat: 40.7128, Lon: -74.0060}
+	suva      = LatLon{Lat: -18.1248, Lon: 178.4501}
+	apia      = LatLon{Lat: -13.8333, Lon: -171.75}
+)
+
+func newGeoTestIndex(t *testing.T) *IndexGeoPoint {
+	idx := NewGeoPointIndex()
+	for docId, v := range []interface{}{
+		map[string]interface{}{"lat": 51.5074, "lon": -0.1278},
+		"48.8566, 2.3522",
+		[]interface{}{json.Number("4.9041"), json.Number("52.3676")},
+		newYork,
+		[]interface{}{"-18.1248,178.4501", map[string]interface{}{"lat": "-13.8333", "lon": "-171.75"}},
+		"gcpvj0duq",
+	} {
+		err := idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	return idx
+}
+
+func TestIndexGeoPoint_Index(t *testing.T) {
+	idx := newGeoTestIndex(t)
+	assert.Equal(t, IdxStats{TermCount: 7}, idx.Stats())
+	assert.Len(t, idx.Locations, 7)
+	points := idx.Points.All()
+	assert.Len(t, points, 7)
+	for i := 1; i < len(points); i++ {
+		assert.True(t, points[i-1].Key.Hi <= points[i].Key.Hi)
+	}
+
+	for _, tcase := range []struct {
+		value interface{}
+		err   error
+	}{
+		{"91,0", errors.New("invalid latitude 91")},
+		{map[string]interface{}{"lat": 0.0, "lon": -180.5}, errors.New("invalid longitude -180.5")},
+		{map[string]interface{}{"lat": 0.0}, errors.New("expected lat and lon")},
+		{map[string]interface{}{"lat": 0.0, "lon": "x"}, errors.New("invalid number x")},
+		{"1,2,3", errors.New("invalid location 1,2,3")},
+		{"a,2", errors.New("invalid location a,2")},
+		{"gcpv!", errors.New("invalid geohash gcpv!")},
+		{"", errors.New("invalid geohash ")},
+		{true, errors.New("expected location, got true")},
+		{[]interface{}{1.0}, errors.New("expected location, got 1")},
+	} {
+		assert.Equal(t, tcase.err, idx.Index(6, tcase.value), tcase.value)
+	}
+}
+
+func TestParseLatLon_Geohash(t *testing.T) {
+	loc, err := ParseLatLon("gcpvj0duq")
+	assert.Nil(t, err)
+	assert.InDelta(t, london.Lat, loc.Lat, 0.0001)
+	assert.InDelta(t, london.Lon, loc.Lon, 0.0001)
+	loc, err = ParseLatLon("U")
+	assert.Nil(t, err)
+	assert.Equal(t, LatLon{Lat: 67.5, Lon: 22.5}, loc)
+}
+
+func TestIndexGeoPoint_GeoBoundingBoxQuery(t *testing.T) {
+	idx := newGeoTestIndex(t)
+	for _, tcase := range []struct {
+		name        string
+		topLeft     LatLon
+		bottomRight LatLon
+		want        NumericResult
+	}{
+		{"europe", LatLon{Lat: 60, Lon: -10}, LatLon{Lat: 40, Lon: 10}, NumericResult{0: 1, 1: 1, 2: 1, 5: 1}},
+		{"london", LatLon{Lat: 51.6, Lon: -0.2}, LatLon{Lat: 51.4, Lon: 0}, NumericResult{0: 1, 5: 1}},
+		{"point", london, london, NumericResult{0: 1}},
+		{"world", LatLon{Lat: 90, Lon: -180}, LatLon{Lat: -90, Lon: 180}, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 2, 5: 1}},
+		{"antimeridian", LatLon{Lat: 0, Lon: 170}, LatLon{Lat: -20, Lon: -170}, NumericResult{4: 2}},
+		{"empty", LatLon{Lat: 10, Lon: 10}, LatLon{Lat: 0, Lon: 20}, NumericResult{}},
+	} {
+		r, err := idx.GeoBoundingBoxQuery(tcase.topLeft, tcase.bottomRight)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+	_, err := idx.GeoBoundingBoxQuery(LatLon{Lat: 0}, LatLon{Lat: 10})
+	assert.Equal(t, errors.New("top is below bottom"), err)
+}
+
+func TestIndexGeoPoint_GeoDistanceQuery(t *testing.T) {
+	idx := newGeoTestIndex(t)
+	for _, tcase := range []struct {
+		name     string
+		origin   LatLon
+		distance float64
+		want     NumericResult
+	}{
+		{"within paris", london, 350000, NumericResult{0: 1, 1: 1, 5: 1}},
+		{"within amsterdam", london, 360000, NumericResult{0: 1, 1: 1, 2: 1, 5: 1}},
+		{"exact", london, 0, NumericResult{0: 1}},
+		{"atlantic", london, 6000000, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 5: 1}},
+		{"antimeridian", suva, 1200000, NumericResult{4: 2}},
+		{"pole", LatLon{Lat: 80, Lon: 0}, 3300000, NumericResult{0: 1, 2: 1, 5: 1}},
+		{"world", london, 30000000, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 2, 5: 1}},
+	} {
+		r, err := idx.GeoDistanceQuery(tcase.origin, tcase.distance)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+	_, err := idx.GeoDistanceQuery(london, -1)
+	assert.Equal(t, errors.New("invalid distance -1"), err)
+}
+
+func TestIndexGeoPoint_GeoDistances(t *testing.T) {
+	idx := newGeoTestIndex(t)
+	d := idx.GeoDistances(london)
+	assert.Len(t, d, 6)
+	assert.InDelta(t, 0, d[0], 1)
+	assert.InDelta(t, 343556, d[1], 1)
+	assert.InDelta(t, 357888, d[2], 1)
+	assert.InDelta(t, 5570230, d[3], 1)
+	// the nearest of several locations
+	assert.Equal(t, haversine(london, apia), d[4])
+	assert.InDelta(t, 1150796, haversine(suva, apia), 1)
+}
+
+func TestParseDistance(t *testing.T) {
+	for _, tcase := range []struct {
+		value interface{}
+		want  float64
+		err   error
+	}{
+		{"12km", 12000, nil},
+		{"1.5mi", 2414.016, nil},
+		{"100 m", 100, nil},
+		{"200", 200, nil},
+		{"1NM", 1852, nil},
+		{float64(50), 50, nil},
+		{json.Number("10"), 10, nil},
+		{"12 furlongs", 0, errors.New("invalid distance 12 furlongs")},
+		{"km", 0, errors.New("invalid distance km")},
+		{true, 0, errors.New("expected number, got true")},
+	} {
+		d, err := ParseDistance(tcase.value)
+		assert.Equal(t, tcase.err, err, tcase.value)
+		assert.InDelta(t, tcase.want, d, 0.000001, tcase.value)
+	}
+}
@@ -0,0 +1,157 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const IP = "ip"
+
+// IndexIP indexes IPv4 and IPv6 addresses as points ordered by address,
+// so that the documents with addresses in a CIDR block or range are found
+// by a binary search for the first address. IPv4 addresses are stored as
+// IPv4-mapped IPv6 addresses
+type IndexIP struct {
+	Points Points
+}
+
+// NewIPIndex creates an index of IP addresses
+func NewIPIndex() *IndexIP {
+	return &IndexIP{}
+}
+
+func (idx *IndexIP) Stats() (stats IdxStats) {
+	points := idx.Points.All()
+	for i, p := range points {
+		if i == 0 || p.Key != points[i-1].Key {
+			stats.TermCount++
+		}
+	}
+	return stats
+}
+
+// Index adds the addresses of a document, given as a string or an array
+// of strings
+func (idx *IndexIP) Index(docId int, content interface{}) error {
+	values, ok := content.([]interface{})
+	if !ok {
+		values = []interface{}{content}
+	}
+	for _, v := range values {
+		addr, err := parseIP(v)
+		if err != nil {
+			return err
+		}
+		idx.Points.insert(docId, addrKey(addr), 0)
+	}
+	return nil
+}
+
+// TermQuery finds documents with the address query, or with an address
+// in the block of a CIDR such as 10.0.0.0/8
+func (idx *IndexIP) TermQuery(query string) (KeywordResult, error) {
+	if strings.Contains(query, "/") {
+		_, block, err := net.ParseCIDR(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %s", query)
+		}
+		var lo, hi [net.IPv6len]byte
+		first := block.IP.Mask(block.Mask)
+		last := make(net.IP, len(first))
+		for i := range first {
+			last[i] = first[i] | ^block.Mask[i]
+		}
+		copy(lo[:], first.To16())
+		copy(hi[:], last.To16())
+		return KeywordResult(idx.addrRange(lo, hi)), nil
+	}
+	addr, err := parseIP(query)
+	if err != nil {
+		return nil, err
+	}
+	return KeywordResult(idx.addrRange(addr, addr)), nil
+}
+
+func (idx *IndexIP) TermsQuery(query []string) (KeywordResult, error) {
+	return termsQuery(query, idx.TermQuery)
+}
+
+// RangeQuery finds documents with addresses within bounds
+func (idx *IndexIP) RangeQuery(b Bounds) (NumericResult, error) {
+	var lo, hi [net.IPv6len]byte
+	for i := range hi {
+		hi[i] = 0xff
+	}
+	for _, bound := range []struct {
+		v     interface{}
+		lower bool
+		open  bool
+	}{{b.Gt, true, true}, {b.Gte, true, false}, {b.Lt, false, true}, {b.Lte, false, false}} {
+		if bound.v == nil {
+			continue
+		}
+		addr, err := parseIP(bound.v)
+		if err != nil {
+			return nil, err
+		}
+		if bound.open {
+			var ok bool
+			if addr, ok = nextAddr(addr, bound.lower); !ok {
+				return NumericResult{}, nil
+			}
+		}
+		if bound.lower && bytes.Compare(addr[:], lo[:]) > 0 {
+			lo = addr
+		}
+		if !bound.lower && bytes.Compare(addr[:], hi[:]) < 0 {
+			hi = addr
+		}
+	}
+	return idx.addrRange(lo, hi), nil
+}
+
+// addrRange finds the documents with addresses from lo to hi inclusive
+func (idx *IndexIP) addrRange(lo [net.IPv6len]byte, hi [net.IPv6len]byte) NumericResult {
+	return idx.Points.keyRange(addrKey(lo), addrKey(hi))
+}
+
+// addrKey orders addresses as 128 bit unsigned integers
+func addrKey(addr [net.IPv6len]byte) PointKey {
+	return PointKey{Hi: binary.BigEndian.Uint64(addr[:8]), Lo: binary.BigEndian.Uint64(addr[8:])}
+}
+
+// nextAddr returns the address after addr, or before it when up is not
+// set, and false when there is none
+func nextAddr(addr [net.IPv6len]byte, up bool) ([net.IPv6len]byte, bool) {
+	for i := len(addr) - 1; i >= 0; i-- {
+		if up {
+			addr[i]++
+			if addr[i] != 0 {
+				return addr, true
+			}
+		} else {
+			addr[i]--
+			if addr[i] != 0xff {
+				return addr, true
+			}
+		}
+	}
+	return addr, false
+}
+
+// parseIP parses an IPv4 or IPv6 address given as a string
+func parseIP(v interface{}) (addr [net.IPv6len]byte, err error) {
+	s, ok := v.(string)
+	if !ok {
+		return addr, fmt.Errorf("expected ip address, got %v", v)
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return addr, fmt.Errorf("invalid ip address %s", s)
+	}
+	copy(addr[:], ip.To16())
+	return addr, nil
+}
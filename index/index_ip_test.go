@@ -0,0 +1,67 @@
+package index
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndexIP(t *testing.T) {
+	idx := NewIPIndex()
+	for docId, v := range []interface{}{
+		"10.1.2.3",
+		"192.168.0.1",
+		"2001:db8::1",
+		[]interface{}{"10.255.255.255", "::ffff:192.168.0.1"},
+		"11.0.0.0",
+	} {
+		err := idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, IdxStats{TermCount: 5}, idx.Stats())
+
+	for _, tcase := range []struct {
+		query string
+		want  KeywordResult
+	}{
+		{"10.1.2.3", KeywordResult{0: 1}},
+		{"192.168.0.1", KeywordResult{1: 1, 3: 1}},
+		{"2001:db8:0:0::1", KeywordResult{2: 1}},
+		{"10.1.2.4", KeywordResult{}},
+		{"10.0.0.0/8", KeywordResult{0: 1, 3: 1}},
+		{"192.168.0.0/16", KeywordResult{1: 1, 3: 1}},
+		{"2001:db8::/32", KeywordResult{2: 1}},
+		{"0.0.0.0/0", KeywordResult{0: 1, 1: 1, 3: 2, 4: 1}},
+	} {
+		r, err := idx.TermQuery(tcase.query)
+		assert.Nil(t, err, tcase.query)
+		assert.Equal(t, tcase.want, r, tcase.query)
+	}
+	r, err := idx.TermsQuery([]string{"10.1.2.3", "11.0.0.0/24"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 4: 1}, r)
+
+	for _, tcase := range []struct {
+		name   string
+		bounds Bounds
+		want   NumericResult
+	}{
+		{"all", Bounds{}, NumericResult{0: 1, 1: 1, 2: 1, 3: 2, 4: 1}},
+		{"gte lt", Bounds{Gte: "10.1.2.3", Lt: "11.0.0.0"}, NumericResult{0: 1, 3: 1}},
+		{"gt lte", Bounds{Gt: "10.1.2.3", Lte: "11.0.0.0"}, NumericResult{3: 1, 4: 1}},
+		{"gt max", Bounds{Gt: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}, NumericResult{}},
+		{"lt min", Bounds{Lt: "::"}, NumericResult{}},
+	} {
+		r, err := idx.RangeQuery(tcase.bounds)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+
+	_, err = idx.TermQuery("10.0.0.0/33")
+	assert.Equal(t, errors.New("invalid cidr 10.0.0.0/33"), err)
+	_, err = idx.TermQuery("10.0.0")
+	assert.Equal(t, errors.New("invalid ip address 10.0.0"), err)
+	_, err = idx.RangeQuery(Bounds{Gt: float64(1)})
+	assert.Equal(t, errors.New("expected ip address, got 1"), err)
+	assert.Equal(t, errors.New("invalid ip address localhost"), idx.Index(5, "localhost"))
+}
@@ -1,7 +1,11 @@
 package index
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/richardjennings/invertedindex/analyser"
+	"io"
+	"io/ioutil"
 	"sort"
 )
 
@@ -32,17 +36,83 @@ func NewKeywordIndex() *IndexKeyword {
 	return &index
 }
 
+// newKeywordAnalyser creates the analyser declared by a keyword field
+// mapping. A mapping may name a normalizer or declare a custom normalizer
+// using the filter key, otherwise values are not normalized. A tokenizer,
+// such as path_hierarchy, may split values into several terms. Names are
+// resolved from analysis before the built-in components, which have their
+// default parameters
+func newKeywordAnalyser(mapping map[string]string, analysis analyser.Analysis) (*analyser.KeywordAnalyser, error) {
+	a := &analyser.KeywordAnalyser{}
+	name, ok := mapping["normalizer"]
+	var chain analyser.Config
+	if !ok {
+		name = "custom"
+		chain = chainConfig(mapping, "filter")
+	}
+	_, filter := mapping["filter"]
+	if ok || filter {
+		n, err := analysis.NewNormalizer(name, chain)
+		if err != nil {
+			return nil, err
+		}
+		a = n
+	}
+	if name, ok := mapping["tokenizer"]; ok {
+		t, err := analysis.NewTokenizer(name)
+		if err != nil {
+			return nil, err
+		}
+		a.Tokenizer = t
+	}
+	return a, nil
+}
+
+// searchAnalyser returns the analyser used for queries, which normalizes
+// but does not tokenize so that a query matches a single indexed term
+func (idx IndexKeyword) searchAnalyser() *analyser.KeywordAnalyser {
+	return &analyser.KeywordAnalyser{TokenFilters: idx.Analyser.TokenFilters}
+}
+
 func (idx *IndexKeyword) Stats() (stats IdxStats) {
 	stats.TermCount = len(idx.TermIndex)
 	return stats
 }
 
 func (idx *IndexKeyword) Index(docId int, content interface{}) error {
-	terms, err := idx.Analyser.Analyse(content)
+	if n, ok := content.(json.Number); ok {
+		content = n.String()
+	}
+	if r, ok := content.(io.Reader); ok {
+		// a keyword is a single value, read whole
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		content = string(b)
+	}
+	if a, ok := content.([]interface{}); ok {
+		// an array of values, such as the values of a field collected
+		// from an array of objects
+		values := make([]string, 0, len(a))
+		for _, v := range a {
+			if n, ok := v.(json.Number); ok {
+				v = n.String()
+			}
+			s, ok := v.(string)
+			if !ok {
+				return errors.New("expecting string or []string")
+			}
+			values = append(values, s)
+		}
+		content = values
+	}
+	tokens, err := idx.Analyser.Analyse(content)
 	if err != nil {
 		return err
 	}
-	for _, term := range terms {
+	for _, token := range tokens {
+		term := token.Term
 		termId, ok := idx.TermIndex[term]
 		if !ok {
 			idx.TermIndex[term] = len(idx.Terms)
@@ -55,14 +125,25 @@ func (idx *IndexKeyword) Index(docId int, content interface{}) error {
 	return nil
 }
 
+// TermQuery finds documents with the value query,
+// normalized in the same way as indexed values
 func (idx *IndexKeyword) TermQuery(query string) (KeywordResult, error) {
-	termId, ok := idx.TermIndex[query]
-	if !ok {
-		return nil, nil
+	tokens, err := idx.searchAnalyser().Analyse(query)
+	if err != nil {
+		return nil, err
 	}
-	result := KeywordResult{}
-	for docId := range idx.Terms[termId] {
-		result[docId] = idx.Terms[termId][docId]
+	var result KeywordResult
+	for _, token := range tokens {
+		termId, ok := idx.TermIndex[token.Term]
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = KeywordResult{}
+		}
+		for docId := range idx.Terms[termId] {
+			result[docId] = idx.Terms[termId][docId]
+		}
 	}
 	return result, nil
 }
@@ -1,7 +1,9 @@
 package index
 
 import (
+	"encoding/json"
 	"errors"
+	"github.com/richardjennings/invertedindex/analyser"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -34,6 +36,58 @@ func TestNewKeywordIndex(t *testing.T) {
 	idx = NewKeywordIndex()
 	_ = idx.Index(0, []string{"a", "a", "a"})
 	assert.Equal(t, 3, idx.Terms[0][0])
+
+	// JSON arrays
+	err = idx.Index(1, []interface{}{"a", json.Number("12")})
+	assert.Nil(t, err)
+	r, err = idx.TermsQuery([]string{"12"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1}, r)
+	err = idx.Index(2, []interface{}{"a", true})
+	assert.Equal(t, errors.New("expecting string or []string"), err)
+}
+
+func TestIndexKeyword_Normalizer(t *testing.T) {
+	cidx, err := NewIndex(map[string]map[string]string{
+		"from":  {"type": "keyword", "normalizer": "lowercase"},
+		"name":  {"type": "keyword", "filter": "trim,lowercase,asciifolding"},
+		"exact": {"type": "keyword"},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"from": "a@b.com", "name": " Zoë ", "exact": "A"})
+	assert.Nil(t, err)
+
+	from := cidx.Idxs["from"].(*IndexKeyword)
+	r, err := from.TermQuery("A@B.com")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+	r, err = from.TermsQuery([]string{"x@y.com", "A@b.COM"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+
+	r, err = cidx.Idxs["name"].(*IndexKeyword).TermQuery("zoe")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+
+	// values are not normalized by default
+	r, err = cidx.Idxs["exact"].(*IndexKeyword).TermQuery("a")
+	assert.Nil(t, err)
+	assert.Nil(t, r)
+
+	// unknown normalizer
+	_, err = NewIndex(map[string]map[string]string{"from": {"type": "keyword", "normalizer": "magic"}})
+	assert.Equal(t, errors.New("unknown normalizer magic"), err)
+
+	// normalizer defined in settings
+	cidx, err = NewIndexWithSettings(map[string]map[string]string{"from": {"type": "keyword", "normalizer": "email"}}, Settings{
+		Analysis: analyser.Analysis{Normalizers: map[string]analyser.Config{"email": {"filter": "trim,lowercase"}}},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"from": "A@B.com "})
+	assert.Nil(t, err)
+	r, err = cidx.Idxs["from"].(*IndexKeyword).TermQuery("a@b.com")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
 }
 
 func TestKeywordResult_Docs(t *testing.T) {
@@ -56,3 +110,48 @@ func TestIndexKeyword_TermsAgg(t *testing.T) {
 
 	assert.Equal(t, KeywordResult{0: 1}, have)
 }
+
+func TestIndexKeyword_PathHierarchy(t *testing.T) {
+	cidx, err := NewIndex(map[string]map[string]string{
+		"category": {"type": "keyword", "tokenizer": "path_hierarchy", "normalizer": "lowercase"},
+	})
+	assert.Nil(t, err)
+	for _, path := range []string{"/Electronics/Laptops/Dell", "/electronics/laptops/apple", "/electronics/phones", "/garden"} {
+		err = cidx.Index(path, map[string]interface{}{"category": path})
+		assert.Nil(t, err)
+	}
+	idx := cidx.Idxs["category"].(*IndexKeyword)
+
+	// a level matches all values below it
+	r, err := idx.TermQuery("/Electronics")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 1: 1, 2: 1}, r)
+
+	// queries are not split into levels
+	r, err = idx.TermQuery("/electronics/laptops")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1, 1: 1}, r)
+	r, err = idx.TermQuery("/electronics/garden")
+	assert.Nil(t, err)
+	assert.Nil(t, r)
+
+	// document counts roll up per level
+	agg, err := idx.TermsAgg()
+	assert.Nil(t, err)
+	counts := map[string]int{}
+	for term, id := range idx.TermIndex {
+		counts[term] = agg[id]
+	}
+	assert.Equal(t, map[string]int{
+		"/electronics":               3,
+		"/electronics/laptops":       2,
+		"/electronics/laptops/dell":  1,
+		"/electronics/laptops/apple": 1,
+		"/electronics/phones":        1,
+		"/garden":                    1,
+	}, counts)
+
+	// unknown tokenizer
+	_, err = NewIndex(map[string]map[string]string{"category": {"type": "keyword", "tokenizer": "magic"}})
+	assert.Equal(t, errors.New("unknown tokenizer magic"), err)
+}
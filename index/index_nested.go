@@ -0,0 +1,135 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	Object = "object"
+	Nested = "nested"
+)
+
+// NestedIndex indexes each object of a nested field as a hidden document,
+// so that a query of the nested index matches the fields of a single
+// object. Fields are named by their full path, such as comments.author
+type NestedIndex struct {
+	*Index
+	// Parents are the parent document of each nested document
+	Parents []int
+}
+
+// newNestedIndexes creates the index of each nested field of cf, which is
+// not within another nested field, from the fields below it and returns
+// the remaining fields
+func (ci *Index) newNestedIndexes(cf map[string]map[string]string, settings Settings) (map[string]map[string]string, error) {
+	var paths []string
+	rest := make(map[string]map[string]string)
+	for field, v := range cf {
+		if v["type"] == Nested {
+			paths = append(paths, field)
+		}
+		rest[field] = v
+	}
+	// outer paths sort before the paths within them
+	sort.Strings(paths)
+	for _, path := range paths {
+		if _, ok := rest[path]; !ok {
+			continue
+		}
+		delete(rest, path)
+		sub := make(map[string]map[string]string)
+		for field, v := range rest {
+			if strings.HasPrefix(field, path+".") {
+				sub[field] = v
+				delete(rest, field)
+			}
+		}
+		idx, err := NewIndexWithSettings(sub, settings)
+		if err != nil {
+			return nil, err
+		}
+		ci.Nested[path] = &NestedIndex{Index: idx}
+	}
+	return rest, nil
+}
+
+// GetNested returns the index of a nested field
+func (ci *Index) GetNested(path string) (*NestedIndex, error) {
+	n, ok := ci.Nested[path]
+	if ok {
+		return n, nil
+	}
+	return nil, errors.New("nested path not found")
+}
+
+// index adds each object of content, an object or an array of objects,
+// as a nested document of the document parent
+func (n *NestedIndex) index(parent int, uri string, path string, content interface{}) error {
+	objects, ok := content.([]interface{})
+	if !ok {
+		objects = []interface{}{content}
+	}
+	for _, o := range objects {
+		obj, ok := o.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("nested field %s expects objects", path)
+		}
+		n.Documents = append(n.Documents, Document{URI: uri})
+		n.Parents = append(n.Parents, parent)
+		if err := n.indexFields(len(n.Documents)-1, uri, path+".", obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flatten adds the values of content to fields by their path below prefix.
+// Objects which are not the value of a field are flattened into the fields
+// of their properties, so that {"user": {"name": "a"}} is the field
+// user.name. The values of a field in an array of objects are collected
+func (ci *Index) flatten(prefix string, content map[string]interface{}, fields map[string][]interface{}) {
+	for name, v := range content {
+		path := prefix + name
+		_, isField := ci.Idxs[path]
+		_, isNested := ci.Nested[path]
+		if isField || isNested {
+			fields[path] = append(fields[path], v)
+			continue
+		}
+		switch v.(type) {
+		case map[string]interface{}:
+			ci.flatten(path+".", v.(map[string]interface{}), fields)
+		case []interface{}:
+			for _, e := range v.([]interface{}) {
+				if obj, ok := e.(map[string]interface{}); ok {
+					ci.flatten(path+".", obj, fields)
+				} else {
+					fields[path] = append(fields[path], e)
+				}
+			}
+		default:
+			fields[path] = append(fields[path], v)
+		}
+	}
+}
+
+// fieldContent returns the content of a field collected from values,
+// combining several values into an array. Arrays of values are combined
+// with the other values unless av reports the array is a single value
+func fieldContent(values []interface{}, av arrayValue) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+	var content []interface{}
+	for _, v := range values {
+		if a, ok := v.([]interface{}); ok && (av == nil || !av.isArrayValue(a)) {
+			content = append(content, a...)
+		} else {
+			content = append(content, v)
+		}
+	}
+	return content
+}
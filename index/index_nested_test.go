@@ -0,0 +1,159 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndex_Object(t *testing.T) {
+	cidx, err := NewIndex(map[string]map[string]string{
+		"user":          {"type": "object"},
+		"user.name":     {"type": "keyword"},
+		"user.age":      {"type": "integer"},
+		"user.location": {"type": "geo_point"},
+		"tags.name":     {"type": "keyword"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, cidx.Idxs, 4)
+
+	err = cidx.Index("1", map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice", "age": 30.0, "location": map[string]interface{}{"lat": 1.0, "lon": 2.0}},
+		"tags": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": []interface{}{"b", "c"}}},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("2", map[string]interface{}{"user.name": "bob"})
+	assert.Nil(t, err)
+
+	r, err := cidx.Idxs["user.name"].(Term).TermQuery("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+	r, err = cidx.Idxs["user.name"].(Term).TermQuery("bob")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1}, r)
+	r, err = cidx.Idxs["user.age"].(Term).TermQuery("30")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+	g, err := cidx.Idxs["user.location"].(GeoBoundingBox).GeoBoundingBoxQuery(LatLon{Lat: 2, Lon: 1}, LatLon{Lat: 0, Lon: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, NumericResult{0: 1}, g)
+	// values of a field within an array of objects are collected
+	r, err = cidx.Idxs["tags.name"].(Terms).TermsQuery([]string{"a", "b", "c"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+	assert.Equal(t, IdxStats{TermCount: 3}, cidx.Idxs["tags.name"].Stats())
+
+	// properties which are not mapped
+	err = cidx.Index("3", map[string]interface{}{"user": map[string]interface{}{"email": "a@b.com"}})
+	assert.Equal(t, errors.New("field not found"), err)
+	err = cidx.Index("4", map[string]interface{}{"user": "alice"})
+	assert.Equal(t, errors.New("field not found"), err)
+}
+
+// test arrays which are a single value, such as a [lon, lat] location, are
+// not combined with the values of other objects in an array of objects
+func TestIndex_ObjectArrayValues(t *testing.T) {
+	cidx, err := NewIndex(map[string]map[string]string{
+		"stops.location": {"type": "geo_point"},
+		"stops.tags":     {"type": "keyword"},
+	})
+	assert.Nil(t, err)
+	n := func(s string) json.Number { return json.Number(s) }
+	err = cidx.Index("1", map[string]interface{}{
+		"stops": []interface{}{
+			map[string]interface{}{"location": []interface{}{n("1"), n("2")}, "tags": []interface{}{"a", "b"}},
+			map[string]interface{}{"location": []interface{}{n("3"), n("4")}, "tags": "c"},
+			map[string]interface{}{"location": []interface{}{[]interface{}{n("5"), n("6")}, "8,7"}},
+		},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("2", map[string]interface{}{
+		"stops": []interface{}{map[string]interface{}{"location": []interface{}{n("9"), n("10")}}},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, IdxStats{TermCount: 5}, cidx.Idxs["stops.location"].Stats())
+	assert.Equal(t, IdxStats{TermCount: 3}, cidx.Idxs["stops.tags"].Stats())
+	for _, tcase := range []struct {
+		topLeft     LatLon
+		bottomRight LatLon
+		want        NumericResult
+	}{
+		{LatLon{Lat: 2.5, Lon: 0.5}, LatLon{Lat: 1.5, Lon: 1.5}, NumericResult{0: 1}},
+		{LatLon{Lat: 4.5, Lon: 2.5}, LatLon{Lat: 3.5, Lon: 3.5}, NumericResult{0: 1}},
+		{LatLon{Lat: 6.5, Lon: 4.5}, LatLon{Lat: 5.5, Lon: 5.5}, NumericResult{0: 1}},
+		{LatLon{Lat: 8.5, Lon: 6.5}, LatLon{Lat: 7.5, Lon: 7.5}, NumericResult{0: 1}},
+		{LatLon{Lat: 10.5, Lon: 8.5}, LatLon{Lat: 9.5, Lon: 9.5}, NumericResult{1: 1}},
+		// no location from a lat and lon of different objects
+		{LatLon{Lat: 3.5, Lon: 1.5}, LatLon{Lat: 2.5, Lon: 3.5}, NumericResult{}},
+	} {
+		r, err := cidx.Idxs["stops.location"].(GeoBoundingBox).GeoBoundingBoxQuery(tcase.topLeft, tcase.bottomRight)
+		assert.Nil(t, err)
+		assert.Equal(t, tcase.want, r, tcase.topLeft)
+	}
+}
+
+func TestIndex_Nested(t *testing.T) {
+	cidx, err := NewIndex(map[string]map[string]string{
+		"title":                    {"type": "text"},
+		"comments":                 {"type": "nested"},
+		"comments.author":          {"type": "keyword"},
+		"comments.stars":           {"type": "integer"},
+		"comments.replies":         {"type": "nested"},
+		"comments.replies.author":  {"type": "keyword"},
+		"comments.replies.visible": {"type": "boolean"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, cidx.Idxs, 1)
+	assert.Len(t, cidx.Nested, 1)
+	comments, err := cidx.GetNested("comments")
+	assert.Nil(t, err)
+	assert.Len(t, comments.Idxs, 2)
+	replies, err := comments.GetNested("comments.replies")
+	assert.Nil(t, err)
+	assert.Len(t, replies.Idxs, 2)
+	_, err = cidx.GetNested("comments.replies")
+	assert.Equal(t, errors.New("nested path not found"), err)
+
+	err = cidx.Index("1", map[string]interface{}{
+		"title": "first",
+		"comments": []interface{}{
+			map[string]interface{}{"author": "alice", "stars": 5.0},
+			map[string]interface{}{
+				"author":  "bob",
+				"stars":   1.0,
+				"replies": map[string]interface{}{"author": "alice", "visible": true},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("2", map[string]interface{}{
+		"comments": map[string]interface{}{"author": "carol", "stars": 3.0},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []Document{{URI: "1"}, {URI: "1"}, {URI: "2"}}, comments.Documents)
+	assert.Equal(t, []int{0, 0, 1}, comments.Parents)
+	assert.Equal(t, []int{1}, replies.Parents)
+	r, err := comments.Idxs["comments.author"].(Term).TermQuery("bob")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1}, r)
+	r, err = replies.Idxs["comments.replies.author"].(Term).TermQuery("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+
+	// nested fields are included in stats
+	stats := cidx.Stats()
+	assert.Equal(t, 2, stats.DocumentCount)
+	assert.Equal(t, IdxStats{TermCount: 3}, stats.Fields["comments.author"])
+	assert.Equal(t, IdxStats{TermCount: 1}, stats.Fields["comments.replies.visible"])
+
+	// errors
+	err = cidx.Index("3", map[string]interface{}{"comments": "alice"})
+	assert.Equal(t, errors.New("nested field comments expects objects"), err)
+	err = cidx.Index("4", map[string]interface{}{"comments": map[string]interface{}{"email": "a@b.com"}})
+	assert.Equal(t, errors.New("field not found"), err)
+	_, err = NewIndex(map[string]map[string]string{"comments": {"type": "nested"}, "comments.author": {"type": "magic"}})
+	assert.Equal(t, errors.New("unknown field type"), err)
+}
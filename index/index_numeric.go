@@ -0,0 +1,321 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	Long    = "long"
+	Integer = "integer"
+	Double  = "double"
+)
+
+// IndexNumeric indexes numbers as points ordered by value. Values are
+// encoded as keys whose unsigned order is the numeric order of the values
+type IndexNumeric struct {
+	Type   string
+	Points Points
+}
+
+type NumericResult map[int]int
+
+func (n NumericResult) Docs() []int {
+	var docs []int
+	for d := range n {
+		docs = append(docs, d)
+	}
+	sort.Ints(docs)
+	return docs
+}
+
+// Bounds are the bounds of a range query, each nil when not set. Bounds
+// are numbers or strings to be parsed according to the type of the field
+type Bounds struct {
+	Gt  interface{}
+	Gte interface{}
+	Lt  interface{}
+	Lte interface{}
+	// Format overrides the format of the field for parsing dates
+	Format string
+}
+
+// NewNumericIndex creates an index of numbers of typ, one of long, integer or double
+func NewNumericIndex(typ string) (*IndexNumeric, error) {
+	switch typ {
+	case Long, Integer, Double:
+		return &IndexNumeric{Type: typ}, nil
+	}
+	return nil, fmt.Errorf("unknown numeric type %s", typ)
+}
+
+func (idx *IndexNumeric) Stats() (stats IdxStats) {
+	points := idx.Points.All()
+	for i, p := range points {
+		if i == 0 || p.Key != points[i-1].Key {
+			stats.TermCount++
+		}
+	}
+	return stats
+}
+
+// Index adds the values of a document, given as a number, a string
+// or an array of either. Fractions of integer values are truncated
+func (idx *IndexNumeric) Index(docId int, content interface{}) error {
+	values, ok := content.([]interface{})
+	if !ok {
+		values = []interface{}{content}
+	}
+	for _, v := range values {
+		key, err := idx.key(v)
+		if err != nil {
+			return err
+		}
+		idx.insert(docId, key)
+	}
+	return nil
+}
+
+// insert adds a point of a document with key
+func (idx *IndexNumeric) insert(docId int, key uint64) {
+	idx.Points.insert(docId, PointKey{Hi: key}, 0)
+}
+
+// key returns the key of a value
+func (idx *IndexNumeric) key(v interface{}) (uint64, error) {
+	if idx.Type == Double {
+		f, err := parseDouble(v)
+		if err != nil {
+			return 0, err
+		}
+		return doubleKey(f), nil
+	}
+	n, f, err := parseLong(v)
+	if err != nil {
+		return 0, err
+	}
+	if float64(n) != f {
+		// not an integer or out of range
+		if f >= math.MaxInt64 || f < math.MinInt64 {
+			return 0, fmt.Errorf("value %v out of range for %s", v, idx.Type)
+		}
+		n = int64(f)
+	}
+	if idx.Type == Integer && (n > math.MaxInt32 || n < math.MinInt32) {
+		return 0, fmt.Errorf("value %v out of range for %s", v, idx.Type)
+	}
+	return longKey(n), nil
+}
+
+// RangeQuery finds documents with values within bounds
+func (idx *IndexNumeric) RangeQuery(b Bounds) (NumericResult, error) {
+	var lo, hi uint64
+	var err error
+	if idx.Type == Double {
+		lo, hi, err = doubleRange(b)
+	} else {
+		lo, hi, err = longRange(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return idx.keyRange(lo, hi), nil
+}
+
+// keyRange finds the documents with keys from lo to hi inclusive
+func (idx *IndexNumeric) keyRange(lo uint64, hi uint64) NumericResult {
+	return idx.Points.keyRange(PointKey{Hi: lo}, PointKey{Hi: hi})
+}
+
+// TermQuery finds documents with the value query
+func (idx *IndexNumeric) TermQuery(query string) (KeywordResult, error) {
+	key, err := idx.key(query)
+	if err != nil {
+		return nil, err
+	}
+	return KeywordResult(idx.keyRange(key, key)), nil
+}
+
+func (idx *IndexNumeric) TermsQuery(query []string) (KeywordResult, error) {
+	return termsQuery(query, idx.TermQuery)
+}
+
+// termsQuery combines the results of a term query for each of query
+func termsQuery(query []string, termQuery func(string) (KeywordResult, error)) (KeywordResult, error) {
+	result := make(KeywordResult)
+	for _, term := range query {
+		r, err := termQuery(term)
+		if err != nil {
+			return nil, err
+		}
+		for docId, v := range r {
+			result[docId] = v
+		}
+	}
+	return result, nil
+}
+
+// longKey orders int64 values as unsigned integers
+func longKey(v int64) uint64 {
+	return uint64(v) ^ 1<<63
+}
+
+// doubleKey orders float64 values as unsigned integers
+func doubleKey(v float64) uint64 {
+	if v == 0 {
+		// -0 and 0 are equal
+		v = 0
+	}
+	bits := math.Float64bits(v)
+	if bits>>63 == 1 {
+		return ^bits
+	}
+	return bits | 1<<63
+}
+
+// parseDouble parses a number given as a JSON number or a string
+func parseDouble(v interface{}) (float64, error) {
+	var f float64
+	var err error
+	switch v.(type) {
+	case float64:
+		f = v.(float64)
+	case int:
+		f = float64(v.(int))
+	case int64:
+		f = float64(v.(int64))
+	case json.Number:
+		f, err = v.(json.Number).Float64()
+	case string:
+		f, err = strconv.ParseFloat(v.(string), 64)
+	default:
+		return 0, fmt.Errorf("expected number, got %v", v)
+	}
+	if err != nil || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid number %v", v)
+	}
+	return f, nil
+}
+
+// parseLong parses an integer given as a JSON number or a string. Values
+// that are not integers are returned as a float64 with n set to 0
+func parseLong(v interface{}) (n int64, f float64, err error) {
+	var s string
+	switch v.(type) {
+	case int:
+		return int64(v.(int)), float64(v.(int)), nil
+	case int64:
+		return v.(int64), float64(v.(int64)), nil
+	case json.Number:
+		s = v.(json.Number).String()
+	case string:
+		s = v.(string)
+	}
+	if s != "" {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, float64(n), nil
+		}
+	}
+	f, err = parseDouble(v)
+	if err != nil {
+		return 0, 0, err
+	}
+	if f == math.Trunc(f) && f < math.MaxInt64 && f >= math.MinInt64 {
+		return int64(f), f, nil
+	}
+	return 0, f, nil
+}
+
+// doubleRange returns the inclusive range of keys within b
+func doubleRange(b Bounds) (uint64, uint64, error) {
+	lo, hi := uint64(0), uint64(math.MaxUint64)
+	for _, bound := range []struct {
+		v     interface{}
+		lower bool
+		open  bool
+	}{{b.Gt, true, true}, {b.Gte, true, false}, {b.Lt, false, true}, {b.Lte, false, false}} {
+		if bound.v == nil {
+			continue
+		}
+		f, err := parseDouble(bound.v)
+		if err != nil {
+			return 0, 0, err
+		}
+		key := doubleKey(f)
+		switch {
+		case bound.lower && bound.open:
+			if key == math.MaxUint64 {
+				return 1, 0, nil
+			}
+			key++
+		case !bound.lower && bound.open:
+			if key == 0 {
+				return 1, 0, nil
+			}
+			key--
+		}
+		if bound.lower && key > lo {
+			lo = key
+		}
+		if !bound.lower && key < hi {
+			hi = key
+		}
+	}
+	return lo, hi, nil
+}
+
+// longRange returns the inclusive range of keys within b, rounding
+// bounds that are not integers to the integers within the range
+func longRange(b Bounds) (uint64, uint64, error) {
+	lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+	for _, bound := range []struct {
+		v     interface{}
+		lower bool
+		open  bool
+	}{{b.Gt, true, true}, {b.Gte, true, false}, {b.Lt, false, true}, {b.Lte, false, false}} {
+		if bound.v == nil {
+			continue
+		}
+		n, f, err := parseLong(bound.v)
+		if err != nil {
+			return 0, 0, err
+		}
+		if float64(n) != f {
+			// not an integer or out of range
+			switch {
+			case bound.lower && f >= math.MaxInt64:
+				return 1, 0, nil
+			case !bound.lower && f < math.MinInt64:
+				return 1, 0, nil
+			case bound.lower && f < math.MinInt64, !bound.lower && f >= math.MaxInt64:
+				continue
+			case bound.lower:
+				n = int64(math.Ceil(f))
+			default:
+				n = int64(math.Floor(f))
+			}
+		} else if bound.open {
+			if bound.lower {
+				if n == math.MaxInt64 {
+					return 1, 0, nil
+				}
+				n++
+			} else {
+				if n == math.MinInt64 {
+					return 1, 0, nil
+				}
+				n--
+			}
+		}
+		if bound.lower && n > lo {
+			lo = n
+		}
+		if !bound.lower && n < hi {
+			hi = n
+		}
+	}
+	return longKey(lo), longKey(hi), nil
+}
@@ -0,0 +1,97 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestIndexNumeric_RangeQuery(t *testing.T) {
+	idx, err := NewNumericIndex(Long)
+	assert.Nil(t, err)
+	for docId, v := range []interface{}{float64(5), json.Number("-3"), "100", []interface{}{float64(7), float64(7.9)}, json.Number("9223372036854775807")} {
+		err = idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, IdxStats{TermCount: 5}, idx.Stats())
+
+	for _, tcase := range []struct {
+		name   string
+		bounds Bounds
+		want   NumericResult
+	}{
+		{"all", Bounds{}, NumericResult{0: 1, 1: 1, 2: 1, 3: 2, 4: 1}},
+		{"gte lte", Bounds{Gte: float64(5), Lte: float64(7)}, NumericResult{0: 1, 3: 2}},
+		{"gt lt", Bounds{Gt: float64(5), Lt: float64(100)}, NumericResult{3: 2}},
+		{"negative", Bounds{Lt: float64(0)}, NumericResult{1: 1}},
+		{"fractions", Bounds{Gt: 4.5, Lt: 5.5}, NumericResult{0: 1}},
+		{"fraction lte", Bounds{Gte: -3.5, Lte: -2.5}, NumericResult{1: 1}},
+		{"strings", Bounds{Gte: "100"}, NumericResult{2: 1, 4: 1}},
+		{"precise", Bounds{Gte: json.Number("9223372036854775807")}, NumericResult{4: 1}},
+		{"above max", Bounds{Gt: json.Number("9223372036854775807")}, NumericResult{}},
+		{"out of range lower", Bounds{Gte: -1e300}, NumericResult{0: 1, 1: 1, 2: 1, 3: 2, 4: 1}},
+		{"out of range upper", Bounds{Gte: 1e300}, NumericResult{}},
+		{"empty", Bounds{Gt: float64(7), Lt: float64(8)}, NumericResult{}},
+	} {
+		r, err := idx.RangeQuery(tcase.bounds)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+
+	_, err = idx.RangeQuery(Bounds{Gt: "a"})
+	assert.Equal(t, errors.New("invalid number a"), err)
+
+	// term queries
+	r, err := idx.TermQuery("7")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{3: 2}, r)
+	r, err = idx.TermsQuery([]string{"-3", "100"})
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1, 2: 1}, r)
+	_, err = idx.TermsQuery([]string{"a"})
+	assert.Equal(t, errors.New("invalid number a"), err)
+
+	// invalid values
+	assert.Equal(t, errors.New("invalid number a"), idx.Index(5, "a"))
+	assert.Equal(t, errors.New("expected number, got true"), idx.Index(5, true))
+	assert.Equal(t, errors.New("value 1e+300 out of range for long"), idx.Index(5, 1e300))
+	idx, _ = NewNumericIndex(Integer)
+	assert.Equal(t, errors.New("value 2147483648 out of range for integer"), idx.Index(0, json.Number("2147483648")))
+
+	_, err = NewNumericIndex("magic")
+	assert.Equal(t, errors.New("unknown numeric type magic"), err)
+}
+
+func TestIndexNumeric_Double(t *testing.T) {
+	idx, err := NewNumericIndex(Double)
+	assert.Nil(t, err)
+	for docId, v := range []interface{}{0.5, -1.25, json.Number("1e3"), math.Copysign(0, -1), "2"} {
+		err = idx.Index(docId, v)
+		assert.Nil(t, err)
+	}
+	for _, tcase := range []struct {
+		name   string
+		bounds Bounds
+		want   NumericResult
+	}{
+		{"all", Bounds{}, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 1}},
+		{"gt", Bounds{Gt: 0.5}, NumericResult{2: 1, 4: 1}},
+		{"gte", Bounds{Gte: 0.5}, NumericResult{0: 1, 2: 1, 4: 1}},
+		{"lt", Bounds{Lt: 0.5}, NumericResult{1: 1, 3: 1}},
+		{"zero", Bounds{Gte: float64(0), Lte: float64(0)}, NumericResult{3: 1}},
+		{"negative", Bounds{Gt: -2.0, Lt: -1.0}, NumericResult{1: 1}},
+		{"infinite", Bounds{Lt: math.Inf(1)}, NumericResult{0: 1, 1: 1, 2: 1, 3: 1, 4: 1}},
+	} {
+		r, err := idx.RangeQuery(tcase.bounds)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+	_, err = idx.RangeQuery(Bounds{Lt: "a"})
+	assert.Equal(t, errors.New("invalid number a"), err)
+}
+
+func TestNumericResult_Docs(t *testing.T) {
+	assert.Equal(t, []int{1, 2}, NumericResult{2: 1, 1: 3}.Docs())
+}
@@ -1,8 +1,12 @@
 package index
 
 import (
+	"encoding/json"
 	"errors"
+	"github.com/richardjennings/invertedindex/analyser"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -49,4 +53,232 @@ func TestNewIndex(t *testing.T) {
 	_, err = NewIndex(map[string]map[string]string{"field": {"type": "magic"}})
 	assert.Equal(t, errors.New("unknown field type"), err)
 
+	// numeric types
+	cidx, err = NewIndex(map[string]map[string]string{"price": {"type": "double"}, "count": {"type": "integer"}, "id": {"type": "long"}})
+	assert.Nil(t, err)
+	assert.Equal(t, &IndexNumeric{Type: Double}, cidx.Idxs["price"])
+	assert.Equal(t, &IndexNumeric{Type: Integer}, cidx.Idxs["count"])
+	assert.Equal(t, &IndexNumeric{Type: Long}, cidx.Idxs["id"])
+
+	// date type
+	cidx, err = NewIndex(map[string]map[string]string{"published": {"type": "date"}, "updated": {"type": "date", "format": "dd/MM/yyyy"}})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultDateFormat, cidx.Idxs["published"].(*IndexDate).Format)
+	assert.Equal(t, "dd/MM/yyyy", cidx.Idxs["updated"].(*IndexDate).Format)
+	_, err = NewIndex(map[string]map[string]string{"published": {"type": "date", "format": "yyyy-ww"}})
+	assert.Equal(t, errors.New("unknown date format yyyy-ww"), err)
+
+	// boolean and ip types
+	cidx, err = NewIndex(map[string]map[string]string{"active": {"type": "boolean"}, "client": {"type": "ip"}})
+	assert.Nil(t, err)
+	assert.Equal(t, NewBooleanIndex(), cidx.Idxs["active"])
+	assert.Equal(t, NewIPIndex(), cidx.Idxs["client"])
+
+	// numbers indexed by keyword and text fields
+	cidx, err = NewIndex(map[string]map[string]string{"code": {"type": "keyword"}, "body": {"type": "text"}})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"code": json.Number("12"), "body": json.Number("34")})
+	assert.Nil(t, err)
+	r, err := cidx.Idxs["code"].(Term).TermQuery("12")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{0: 1}, r)
+	m, err := cidx.Idxs["body"].(Match).MatchQuery("34")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, m)
+
+	// token limit
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "max_token_count": "10"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, cidx.Idxs["field"].(*IndexText).MaxTokens)
+	_, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "max_token_count": "0"}})
+	assert.Equal(t, errors.New("invalid max_token_count 0"), err)
+
+	// position gap between values
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text"}, "gap": {"type": "text", "position_increment_gap": "0"}})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultPositionIncrementGap, cidx.Idxs["field"].(*IndexText).PositionIncrementGap)
+	assert.Equal(t, 0, cidx.Idxs["gap"].(*IndexText).PositionIncrementGap)
+	_, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "position_increment_gap": "-1"}})
+	assert.Equal(t, errors.New("invalid position_increment_gap -1"), err)
+
+}
+
+func TestNewIndex_Analyser(t *testing.T) {
+	// named analyser
+	cidx, err := NewIndex(map[string]map[string]string{"field": {"type": "text", "analyzer": "whitespace"}})
+	assert.Nil(t, err)
+	assert.Equal(t, analyser.FullTextAnalyser{Tokenizer: analyser.WhitespaceTokenizer{}}, cidx.Idxs["field"].(*IndexText).Analyser)
+
+	// custom analysis chain
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "tokenizer": "whitespace"}})
+	assert.Nil(t, err)
+	assert.Equal(t, analyser.FullTextAnalyser{Tokenizer: analyser.WhitespaceTokenizer{}}, cidx.Idxs["field"].(*IndexText).Analyser)
+
+	// analysis is applied to both content and queries
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "tokenizer": "standard", "filter": "lowercase,asciifolding"}})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "Le Café"})
+	assert.Nil(t, err)
+	r, err := cidx.Idxs["field"].(Match).MatchQuery("CAFE")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+
+	// stemming with protected words
+	cidx, err = NewIndexWithSettings(map[string]map[string]string{"field": {"type": "text", "analyzer": "protected"}}, Settings{Analysis: analyser.Analysis{
+		Analysers: map[string]analyser.Config{"protected": {"type": "english", "stem_exclusion": "galaxies"}},
+	}})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "running galaxies"})
+	assert.Nil(t, err)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("runs galaxy")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+
+	// search as you type with a different search analyser
+	cidx, err = NewIndexWithSettings(map[string]map[string]string{"field": {
+		"type":            "text",
+		"tokenizer":       "autocomplete",
+		"filter":          "lowercase",
+		"search_analyzer": "standard",
+	}}, Settings{Analysis: analyser.Analysis{
+		Tokenizers: map[string]analyser.Config{
+			"autocomplete": {"type": "edge_ngram", "min_gram": "2", "max_gram": "10", "token_chars": "letter,digit"},
+		},
+	}})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "Dell Latitude"})
+	assert.Nil(t, err)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("lati")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("l")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{}, r)
+
+	// shingles match adjacent terms
+	cidx, err = NewIndexWithSettings(map[string]map[string]string{"field": {
+		"type":      "text",
+		"tokenizer": "standard",
+		"filter":    "lowercase,shingles",
+	}}, Settings{Analysis: analyser.Analysis{
+		Filters: map[string]analyser.Config{"shingles": {"type": "shingle", "output_unigrams": "false"}},
+	}})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "Full text search"})
+	assert.Nil(t, err)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("text search")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("full search")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{}, r)
+
+	// unknown search analyser
+	_, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "search_analyzer": "magic"}})
+	assert.Equal(t, errors.New("unknown analyser magic"), err)
+
+	// unknown analyser
+	_, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "analyzer": "magic"}})
+	assert.Equal(t, errors.New("unknown analyser magic"), err)
+
+	// index options
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "index_options": "offsets"}})
+	assert.Nil(t, err)
+	assert.True(t, cidx.Idxs["field"].(*IndexText).StoreOffsets)
+	cidx, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "index_options": "positions"}})
+	assert.Nil(t, err)
+	assert.False(t, cidx.Idxs["field"].(*IndexText).StoreOffsets)
+	_, err = NewIndex(map[string]map[string]string{"field": {"type": "text", "index_options": "magic"}})
+	assert.Equal(t, errors.New("unknown index_options magic"), err)
+}
+
+func TestNewIndexWithSettings(t *testing.T) {
+	settings := Settings{Analysis: analyser.Analysis{
+		Filters: map[string]analyser.Config{
+			"synonyms": {"type": "synonym", "synonyms": "tv, television", "expand": false},
+		},
+		Analysers: map[string]analyser.Config{
+			"media": {"tokenizer": "standard", "filter": "lowercase,synonyms"},
+		},
+	}}
+	cidx, err := NewIndexWithSettings(map[string]map[string]string{"field": {"type": "text", "analyzer": "media"}}, settings)
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "Smart TV"})
+	assert.Nil(t, err)
+	r, err := cidx.Idxs["field"].(Match).MatchQuery("television")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+
+	// synonyms expanded only at search time
+	cidx, err = NewIndexWithSettings(map[string]map[string]string{"field": {"type": "text", "search_analyzer": "media"}}, settings)
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"field": "Smart TV"})
+	assert.Nil(t, err)
+	r, err = cidx.Idxs["field"].(Match).MatchQuery("Television")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, r)
+
+	// invalid settings
+	settings.Analysis.Filters["broken"] = analyser.Config{"type": "magic"}
+	_, err = NewIndexWithSettings(map[string]map[string]string{}, settings)
+	assert.Equal(t, errors.New("unknown token filter magic"), err)
+}
+
+func TestIndex_SubFields(t *testing.T) {
+	cidx, err := NewIndexWithSettings(map[string]map[string]string{
+		"name":          {"type": "keyword", "fields": "phonetic, soundex"},
+		"name.phonetic": {"type": "text", "tokenizer": "standard", "filter": "phonetic"},
+		"name.soundex":  {"type": "text", "tokenizer": "standard", "filter": "soundex"},
+		"user":          {"type": "keyword"},
+		"user.name":     {"type": "keyword"},
+		"body":          {"type": "text", "max_token_count": "1", "fields": "all"},
+		"body.all":      {"type": "text"},
+		"price":         {"type": "text", "fields": "value"},
+		"price.value":   {"type": "long"},
+	}, Settings{Analysis: analyser.Analysis{
+		Filters: map[string]analyser.Config{"soundex": {"type": "phonetic", "encoder": "soundex"}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name.phonetic", "name.soundex"}, cidx.MultiFields["name"])
+	assert.Nil(t, cidx.MultiFields["name.phonetic"])
+	// fields named by an object path are not multi-fields
+	assert.Nil(t, cidx.MultiFields["user"])
+
+	err = cidx.Index("1", map[string]interface{}{"name": "Stroustrup"})
+	assert.Nil(t, err)
+	err = cidx.Index("2", map[string]interface{}{"name": ioutil.NopCloser(strings.NewReader("Ritchie"))})
+	assert.Nil(t, err)
+
+	// the field is indexed as given
+	r, err := cidx.Idxs["name"].(Term).TermQuery("Ritchie")
+	assert.Nil(t, err)
+	assert.Equal(t, KeywordResult{1: 1}, r)
+
+	// misspellings match by sound
+	m, err := cidx.Idxs["name.phonetic"].(Match).MatchQuery("Strostrup")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1}}, m)
+	m, err = cidx.Idxs["name.soundex"].(Match).MatchQuery("Ritchy")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{1: {1}}, m)
+
+	// a reader is indexed by each field as it is read, multi-fields
+	// index content the field stops reading
+	content := strings.Repeat("a b ", 100000) + "c"
+	err = cidx.Index("3", map[string]interface{}{"body": ioutil.NopCloser(strings.NewReader(content))})
+	assert.Nil(t, err)
+	m, err = cidx.Idxs["body"].(Match).MatchQuery("a c")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{2: {1}}, m)
+	m, err = cidx.Idxs["body.all"].(Match).MatchQuery("a c")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{2: {100000, 1}}, m)
+
+	// a multi-field failing to index content does not block the field
+	err = cidx.Index("4", map[string]interface{}{"price": ioutil.NopCloser(strings.NewReader(content))})
+	assert.NotNil(t, err)
+
+	// multi-fields must be mapped
+	_, err = NewIndex(map[string]map[string]string{"name": {"type": "keyword", "fields": "raw"}})
+	assert.Equal(t, errors.New("missing mapping for multi-field name.raw"), err)
 }
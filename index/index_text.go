@@ -1,23 +1,106 @@
 package index
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/richardjennings/invertedindex/analyser"
 	"sort"
 )
 
 type IndexText struct {
 	TermIndex map[string]int
-	Terms     []map[int]map[int]int
-	Analyser  analyser.FullTextAnalyser
+	// Terms are the positions of each term by term id and document
+	Terms    []map[int]map[int]struct{}
+	Analyser analyser.FullTextAnalyser
+	// SearchAnalyser analyses queries when set, otherwise Analyser is used
+	SearchAnalyser *analyser.FullTextAnalyser
+	// StoreOffsets enables storing the offsets of terms in Offsets
+	StoreOffsets bool
+	// Offsets of each term by term id, document and position
+	Offsets []map[int]map[int]Offset
+	// MaxTokens is the number of tokens of a document indexed when
+	// greater than 0, the rest of the content is ignored
+	MaxTokens int
+	// PositionIncrementGap is the number of positions between the
+	// values of an array
+	PositionIncrementGap int
 }
 
+// Offset is the range of bytes in the original content a term was produced from
+type Offset struct {
+	Start int
+	End   int
+}
+
+// index_options of a text field mapping
+const (
+	IndexOptionsPositions = "positions"
+	IndexOptionsOffsets   = "offsets"
+)
+
+// DefaultPositionIncrementGap is the default number of positions
+// between the values of an array
+const DefaultPositionIncrementGap = 100
+
 // NewTextIndex creates a new index struct
 func NewTextIndex() *IndexText {
 	index := IndexText{}
 	index.TermIndex = make(map[string]int)
+	index.PositionIncrementGap = DefaultPositionIncrementGap
 	return &index
 }
 
+// newTextAnalysers creates the analysis chains declared by a text field
+// mapping. A mapping may name an analyzer or declare a custom chain using
+// the char_filter, tokenizer and filter keys. A different analyzer for
+// queries may be named by search_analyzer, otherwise the returned search
+// analyser is nil. Names are resolved from analysis before the built-in
+// components, which have their default parameters
+func newTextAnalysers(mapping map[string]string, analysis analyser.Analysis) (*analyser.FullTextAnalyser, *analyser.FullTextAnalyser, error) {
+	name, ok := mapping["analyzer"]
+	var chain analyser.Config
+	if !ok {
+		name = analyser.DefaultAnalyser
+		if _, ok := mapping["tokenizer"]; ok {
+			name = "custom"
+			chain = chainConfig(mapping, "char_filter", "tokenizer", "filter")
+		}
+	}
+	a, err := analysis.NewAnalyser(name, chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, ok = mapping["search_analyzer"]
+	if !ok {
+		return a, nil, nil
+	}
+	s, err := analysis.NewAnalyser(name, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, s, nil
+}
+
+// chainConfig returns the keys of a field mapping naming the components
+// of an analysis chain
+func chainConfig(mapping map[string]string, keys ...string) analyser.Config {
+	cfg := analyser.Config{}
+	for _, k := range keys {
+		if v, ok := mapping[k]; ok {
+			cfg[k] = v
+		}
+	}
+	return cfg
+}
+
+// searchAnalyser returns the analyser used for queries
+func (idx IndexText) searchAnalyser() *analyser.FullTextAnalyser {
+	if idx.SearchAnalyser != nil {
+		return idx.SearchAnalyser
+	}
+	return &idx.Analyser
+}
+
 type TermFreqResult map[int][]int
 
 func (p TermFreqResult) Docs() []int {
@@ -49,50 +132,91 @@ func (idx *IndexText) Stats() (stats IdxStats) {
 	return stats
 }
 
-// IndexDocument adds a document to the inverted index
+// IndexDocument adds a document to the inverted index. Tokens are
+// indexed as they are produced, so content given as an io.Reader
+// is not read into memory. Each value of an array is indexed after
+// a gap of PositionIncrementGap positions following the previous
+// value, so that phrases do not match across values
 func (idx *IndexText) Index(docId int, content interface{}) error {
-	terms, err := idx.Analyser.Analyse(content)
-	if err != nil {
-		return err
-	}
-	pretid := -1
+	count := 0
 
-	for j := 0; j < len(terms); j++ {
+	values, ok := content.([]interface{})
+	if !ok {
+		values = []interface{}{content}
+	}
 
-		// look up term id
-		tid, ok := idx.TermIndex[terms[j]]
-		if !ok {
-			tid = len(idx.TermIndex)
-			idx.TermIndex[terms[j]] = tid
-			d := make(map[int]map[int]int)
-			idx.Terms = append(idx.Terms, d)
+	// the position and offset of the start of each value and the
+	// last position and end offset of the values so far
+	position, offset := 0, 0
+	last, end := -1, 0
+	for _, v := range values {
+		if idx.MaxTokens > 0 && count >= idx.MaxTokens {
+			break
 		}
-
-		// update previous term with next (this) term
-		if pretid != -1 {
-			idx.Terms[pretid][docId][j-1] = tid
+		if n, ok := v.(json.Number); ok {
+			v = n.String()
 		}
-
-		if _, ok := idx.Terms[tid][docId]; !ok {
-			idx.Terms[tid][docId] = make(map[int]int)
+		if last >= 0 {
+			position = last + idx.PositionIncrementGap + 1
+			offset = end + 1
 		}
+		err := idx.Analyser.AnalyseStream(v, func(token analyser.Token) bool {
+			token.Position += position
+			token.Start += offset
+			token.End += offset
+			if token.Position > last {
+				last = token.Position
+			}
+			if token.End > end {
+				end = token.End
+			}
 
-		// set term doc pos with placeholder next tid
-		idx.Terms[tid][docId][j] = 0
-		pretid = tid
-	}
+			// look up term id
+			tid, ok := idx.TermIndex[token.Term]
+			if !ok {
+				tid = len(idx.TermIndex)
+				idx.TermIndex[token.Term] = tid
+				d := make(map[int]map[int]struct{})
+				idx.Terms = append(idx.Terms, d)
+				if idx.StoreOffsets {
+					idx.Offsets = append(idx.Offsets, make(map[int]map[int]Offset))
+				}
+			}
 
+			if _, ok := idx.Terms[tid][docId]; !ok {
+				idx.Terms[tid][docId] = make(map[int]struct{})
+			}
+			idx.Terms[tid][docId][token.Position] = struct{}{}
+			if idx.StoreOffsets {
+				if _, ok := idx.Offsets[tid][docId]; !ok {
+					idx.Offsets[tid][docId] = make(map[int]Offset)
+				}
+				idx.Offsets[tid][docId][token.Position] = Offset{Start: token.Start, End: token.End}
+			}
+			count++
+			return idx.MaxTokens <= 0 || count < idx.MaxTokens
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // MatchQuery looks up a terms in the inverted index and returns
 // the associated documents and position data
 func (idx IndexText) MatchQuery(query string) (TermFreqResult, error) {
+	return idx.MatchQueryWithAnalyser(query, idx.searchAnalyser())
+}
+
+// MatchQueryWithAnalyser is MatchQuery analysing the query with a
+// in place of the search analyser of the field
+func (idx IndexText) MatchQueryWithAnalyser(query string, a *analyser.FullTextAnalyser) (TermFreqResult, error) {
 	//nilResult := make(map[int]map[int]int)
 	result := make(TermFreqResult)
 
 	// tokenize the query
-	terms, err := idx.Analyser.Analyse(query)
+	terms, err := a.Terms(query)
 	if err != nil {
 		return nil, err
 	}
@@ -124,72 +248,110 @@ func (idx IndexText) MatchQuery(query string) (TermFreqResult, error) {
 
 // phraseQuery uses position data in the inverted index
 // to find documents that have a sequence of terms
-// where the positions of terms in order is incremental
+// where the positions of terms are in the same order
+// and the same distance apart as in the query
 func (idx IndexText) PhraseQuery(query string) (PostingResult, error) {
+	return idx.PhraseQueryWithAnalyser(query, idx.searchAnalyser())
+}
+
+// PhraseQueryWithAnalyser is PhraseQuery analysing the query with a
+// in place of the search analyser of the field
+func (idx IndexText) PhraseQueryWithAnalyser(query string, a *analyser.FullTextAnalyser) (PostingResult, error) {
 	// initialize result
-	//result := make(map[int][]int)
 	result := make(PostingResult)
 
 	// tokenize query
-	terms, err := idx.Analyser.Analyse(query)
+	tokens, err := a.Analyse(query)
 	if err != nil {
 		return nil, err
 	}
-	lenTerms := len(terms)
+	if len(tokens) == 0 {
+		return result, nil
+	}
 
-	// look up termIds from index
-	var termIds = make(map[int]int)
-	for i, t := range terms {
-		id, ok := idx.TermIndex[t]
-		if !ok {
+	// group termIds by position relative to the first token,
+	// any of the terms at a position may match
+	type clause struct {
+		offset  int
+		termIds []int
+	}
+	var clauses []clause
+	for _, t := range tokens {
+		offset := t.Position - tokens[0].Position
+		if len(clauses) == 0 || clauses[len(clauses)-1].offset != offset {
+			clauses = append(clauses, clause{offset: offset})
+		}
+		if id, ok := idx.TermIndex[t.Term]; ok {
+			c := &clauses[len(clauses)-1]
+			c.termIds = append(c.termIds, id)
+		}
+	}
+	for _, c := range clauses {
+		if len(c.termIds) == 0 {
 			return result, nil
 		}
-		termIds[i] = id
 	}
 
-OUTER:
-	// iterate all document matches for term 0
-	for docId, postings := range idx.Terms[termIds[0]] {
-		// check match for document against all terms
-		for i := 1; i < lenTerms; i++ {
-			_, ok := idx.Terms[termIds[i]][docId]
-			if !ok {
-				continue OUTER
+	// hasPosting checks for any of termIds at pos in the document
+	hasPosting := func(termIds []int, docId int, pos int) bool {
+		for _, id := range termIds {
+			if _, ok := idx.Terms[id][docId][pos]; ok {
+				return true
 			}
 		}
-	POSTING:
-		// now check that for this document, there is a position match for each term posting
-		for posting, ntid := range postings {
-			// use the next term info to skip if needed
-			if ntid != termIds[1] {
-				continue
-			}
-
-			for i := 1; i < lenTerms; i++ {
-				// check for term in position
-				ntid, ok := idx.Terms[termIds[i]][docId][posting+i]
-				if !ok {
-					continue POSTING
-				}
-
-				// if last iteration, we have a result
-				if i == lenTerms-1 {
-
-					result[docId] = append(result[docId], posting)
-					continue
-				}
+		return false
+	}
 
-				// check next term can match
-				if ntid != termIds[i+1] {
-					continue POSTING
+	// iterate all document matches for the first position
+	for _, termId := range clauses[0].termIds {
+		for docId, postings := range idx.Terms[termId] {
+		POSTING:
+			for posting := range postings {
+				for _, c := range clauses[1:] {
+					if !hasPosting(c.termIds, docId, posting+c.offset) {
+						continue POSTING
+					}
 				}
+				result[docId] = append(result[docId], posting)
 			}
 		}
 	}
 
-	for _, r := range result {
+	for docId, r := range result {
 		sort.Ints(r)
+		// remove duplicates from terms sharing a position
+		j := 0
+		for i := range r {
+			if i == 0 || r[i] != r[j-1] {
+				r[j] = r[i]
+				j++
+			}
+		}
+		result[docId] = r[:j]
 	}
 
 	return result, nil
 }
+
+// TermOffsets returns the offsets of term in a document ordered by
+// position. Offsets are only available when StoreOffsets is set
+func (idx IndexText) TermOffsets(term string, docId int) ([]Offset, error) {
+	if !idx.StoreOffsets {
+		return nil, errors.New("offsets are not stored")
+	}
+	tid, ok := idx.TermIndex[term]
+	if !ok {
+		return nil, nil
+	}
+	offsets := idx.Offsets[tid][docId]
+	positions := make([]int, 0, len(offsets))
+	for pos := range offsets {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+	result := make([]Offset, len(positions))
+	for i, pos := range positions {
+		result[i] = offsets[pos]
+	}
+	return result, nil
+}
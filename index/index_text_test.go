@@ -1,11 +1,15 @@
 package index
 
 import (
+	"encoding/json"
+	"errors"
+	"github.com/richardjennings/invertedindex/analyser"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -45,13 +49,13 @@ func TestIndexTextDocumentTermPostings(t *testing.T) {
 	err := index.Index(0, "1 2 3")
 	assert.Nil(t, err)
 	have := index.Terms[0][0]
-	want := map[int]int{0: 1}
+	want := map[int]struct{}{0: {}}
 	assert.True(t, reflect.DeepEqual(want, have))
 	have = index.Terms[1][0]
-	want = map[int]int{1: 2}
+	want = map[int]struct{}{1: {}}
 	assert.True(t, reflect.DeepEqual(want, have))
 	have = index.Terms[2][0]
-	want = map[int]int{2: 0}
+	want = map[int]struct{}{2: {}}
 	assert.True(t, reflect.DeepEqual(want, have))
 }
 
@@ -160,6 +164,18 @@ func TestIndexText_PhraseQuery(t *testing.T) {
 			"a c d",
 			PostingResult{},
 		},
+		{
+			"first posting not followed by phrase",
+			[]string{"a x a b"},
+			"a b",
+			PostingResult{0: {2}},
+		},
+		{
+			"single term phrase",
+			[]string{"a b a"},
+			"a",
+			PostingResult{0: {0, 2}},
+		},
 		{
 			"query can match the same document more than once",
 			[]string{
@@ -188,6 +204,92 @@ func TestIndexText_PhraseQuery(t *testing.T) {
 	}
 }
 
+func TestIndexText_PhraseQuery_PositionGap(t *testing.T) {
+	cidx, err := NewIndex(Schema{"txt": {"type": Text, "analyzer": "stop"}})
+	assert.Nil(t, err)
+	idx := cidx.Idxs["txt"].(*IndexText)
+	for docId, content := range []string{
+		"the state of the art",
+		"state art",
+		"the state of an art",
+	} {
+		err = idx.Index(docId, content)
+		assert.Nil(t, err)
+	}
+
+	// stop words leave a gap
+	assert.Equal(t, map[int]struct{}{1: {}}, idx.Terms[idx.TermIndex["state"]][0])
+	assert.Equal(t, map[int]struct{}{4: {}}, idx.Terms[idx.TermIndex["art"]][0])
+
+	have, err := idx.PhraseQuery("State of the Art")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {1}, 2: {1}}, have)
+
+	have, err = idx.PhraseQuery("state art")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{1: {0}}, have)
+
+	// query of only stop words
+	have, err = idx.PhraseQuery("the")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{}, have)
+}
+
+func TestIndexText_PhraseQuery_Synonyms(t *testing.T) {
+	cidx, err := NewIndexWithSettings(Schema{"txt": {
+		"type":      Text,
+		"tokenizer": "standard",
+		"filter":    "lowercase,synonyms",
+	}}, Settings{Analysis: analyser.Analysis{
+		Filters: map[string]analyser.Config{"synonyms": {"type": "synonym", "synonyms": "laptop, notebook; nyc => new york city"}},
+	}})
+	assert.Nil(t, err)
+	idx := cidx.Idxs["txt"].(*IndexText)
+	for docId, content := range []string{
+		"I love NYC in spring",
+		"New York City in spring",
+		"a notebook computer",
+	} {
+		err = idx.Index(docId, content)
+		assert.Nil(t, err)
+	}
+
+	have, err := idx.PhraseQuery("nyc in spring")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {2}, 1: {0}}, have)
+
+	have, err = idx.PhraseQuery("new york city")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {2}, 1: {0}}, have)
+
+	have, err = idx.PhraseQuery("laptop computer")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{2: {1}}, have)
+
+	r, err := idx.MatchQuery("laptop")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{2: {1, 1}}, r)
+
+	// multi word synonyms only used by the search analyser
+	cidx, err = NewIndexWithSettings(Schema{"txt": {"type": Text, "search_analyzer": "cities"}}, Settings{Analysis: analyser.Analysis{
+		Filters:   map[string]analyser.Config{"cities": {"type": "synonym", "synonyms": "ny, new york"}},
+		Analysers: map[string]analyser.Config{"cities": {"tokenizer": "standard", "filter": "lowercase,cities"}},
+	}})
+	assert.Nil(t, err)
+	idx = cidx.Idxs["txt"].(*IndexText)
+	for docId, content := range []string{
+		"ny pizza",
+		"new york pizza",
+		"ny style pizza",
+	} {
+		err = idx.Index(docId, content)
+		assert.Nil(t, err)
+	}
+	have, err = idx.PhraseQuery("ny pizza")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {0}, 1: {0}}, have)
+}
+
 func BenchmarkIndexText_MatchQuery(b *testing.B) {
 
 	file, err := os.Open("../../test/corpus/the-comedy-of-errors.txt")
@@ -238,6 +340,118 @@ func BenchmarkIndexText_PhraseQuery(b *testing.B) {
 	}
 }
 
+func TestIndexText_TermOffsets(t *testing.T) {
+	idx := NewTextIndex()
+	idx.StoreOffsets = true
+	err := idx.Index(0, "the cat sat on the mat")
+	assert.Nil(t, err)
+	err = idx.Index(1, "the end")
+	assert.Nil(t, err)
+
+	offsets, err := idx.TermOffsets("the", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Offset{{Start: 0, End: 3}, {Start: 15, End: 18}}, offsets)
+
+	offsets, err = idx.TermOffsets("the", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Offset{{Start: 0, End: 3}}, offsets)
+
+	// term not in document
+	offsets, err = idx.TermOffsets("end", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Offset{}, offsets)
+
+	// term not in index
+	offsets, err = idx.TermOffsets("dog", 0)
+	assert.Nil(t, err)
+	assert.Nil(t, offsets)
+
+	// offsets not stored
+	idx = NewTextIndex()
+	err = idx.Index(0, "the cat")
+	assert.Nil(t, err)
+	assert.Nil(t, idx.Offsets)
+	_, err = idx.TermOffsets("the", 0)
+	assert.Equal(t, "offsets are not stored", err.Error())
+}
+
+func TestIndexText_MaxTokens(t *testing.T) {
+	idx := NewTextIndex()
+	idx.MaxTokens = 2
+	err := idx.Index(0, ioutil.NopCloser(strings.NewReader("a b c d")))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"a": 0, "b": 1}, idx.TermIndex)
+
+	// each document has its own limit
+	err = idx.Index(1, "b c")
+	assert.Nil(t, err)
+	r, err := idx.MatchQuery("c")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{1: {1}}, r)
+
+	// large content is streamed
+	idx = NewTextIndex()
+	err = idx.Index(0, strings.NewReader(strings.Repeat("a b c\n", 100000)+"d"))
+	assert.Nil(t, err)
+	r, err = idx.MatchQuery("c d")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {100000, 1}}, r)
+	p, err := idx.PhraseQuery("c d")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {299999}}, p)
+}
+
+func TestIndexText_MultiValued(t *testing.T) {
+	idx := NewTextIndex()
+	idx.StoreOffsets = true
+	err := idx.Index(0, []interface{}{"a b", "c d", json.Number("12")})
+	assert.Nil(t, err)
+	err = idx.Index(1, "a b c d")
+	assert.Nil(t, err)
+
+	// phrases do not match across values
+	p, err := idx.PhraseQuery("b c")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{1: {1}}, p)
+	p, err = idx.PhraseQuery("c d")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {102}, 1: {2}}, p)
+	p, err = idx.PhraseQuery("12")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {204}}, p)
+	r, err := idx.MatchQuery("a d")
+	assert.Nil(t, err)
+	assert.Equal(t, TermFreqResult{0: {1, 1}, 1: {1, 1}}, r)
+
+	// offsets of each value follow the previous value
+	o, err := idx.TermOffsets("d", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Offset{{Start: 6, End: 7}}, o)
+	o, err = idx.TermOffsets("12", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Offset{{Start: 8, End: 10}}, o)
+
+	// without a gap values are adjacent
+	idx = NewTextIndex()
+	idx.PositionIncrementGap = 0
+	err = idx.Index(0, []interface{}{"a b", "c d"})
+	assert.Nil(t, err)
+	p, err = idx.PhraseQuery("b c")
+	assert.Nil(t, err)
+	assert.Equal(t, PostingResult{0: {1}}, p)
+
+	// the token limit applies to all values
+	idx = NewTextIndex()
+	idx.MaxTokens = 3
+	err = idx.Index(0, []interface{}{"a b", "c d", "e"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"a": 0, "b": 1, "c": 2}, idx.TermIndex)
+
+	// invalid values
+	err = idx.Index(1, []interface{}{"a", true})
+	assert.Equal(t, errors.New("string or io.Reader type required"), err)
+}
+
 func TestTermFreqResult_Docs(t *testing.T) {
 	p := TermFreqResult{3: {2, 6, 3}, 2: {1, 7, 9}, 1: {3, 1, 2}}
 	assert.Equal(t, []int{1, 2, 3}, p.Docs())
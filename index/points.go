@@ -0,0 +1,89 @@
+package index
+
+import (
+	"sort"
+)
+
+// Points are the values of documents as points ordered by key, so that the
+// documents with values in a range are found by a binary search for the
+// start of the range. Points are kept in order as they are added, so that
+// searches only read them
+type Points struct {
+	points []Point
+}
+
+// Point is a value of a document. Ref is the index of the value in the
+// values kept by an index whose key does not hold the whole value
+type Point struct {
+	Key   PointKey
+	DocId int
+	Ref   int
+}
+
+// PointKey orders points by Hi and then Lo as unsigned integers
+type PointKey struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Less reports whether k orders before o
+func (k PointKey) Less(o PointKey) bool {
+	return k.Hi < o.Hi || (k.Hi == o.Hi && k.Lo < o.Lo)
+}
+
+// pointLess orders points by key and then by document
+func pointLess(a Point, b Point) bool {
+	if a.Key != b.Key {
+		return a.Key.Less(b.Key)
+	}
+	return a.DocId < b.DocId
+}
+
+// Len returns the number of points
+func (p *Points) Len() int {
+	return len(p.points)
+}
+
+// All returns the points in order
+func (p *Points) All() []Point {
+	return p.points
+}
+
+// insert adds a point of a document with key referring to value ref
+func (p *Points) insert(docId int, key PointKey, ref int) {
+	point := Point{Key: key, DocId: docId, Ref: ref}
+	n := len(p.points)
+	if n == 0 || !pointLess(point, p.points[n-1]) {
+		// added in order, as when documents are indexed with increasing values
+		p.points = append(p.points, point)
+		return
+	}
+	i := sort.Search(n, func(i int) bool {
+		return pointLess(point, p.points[i])
+	})
+	p.points = append(p.points, Point{})
+	copy(p.points[i+1:], p.points[i:])
+	p.points[i] = point
+}
+
+// Range calls fn with each point with a key from lo to hi inclusive in order
+func (p *Points) Range(lo PointKey, hi PointKey, fn func(Point)) {
+	if hi.Less(lo) {
+		return
+	}
+	i := sort.Search(len(p.points), func(i int) bool {
+		return !p.points[i].Key.Less(lo)
+	})
+	for ; i < len(p.points) && !hi.Less(p.points[i].Key); i++ {
+		fn(p.points[i])
+	}
+}
+
+// keyRange finds the documents with keys from lo to hi inclusive
+func (p *Points) keyRange(lo PointKey, hi PointKey) NumericResult {
+	result := NumericResult{}
+	p.Range(lo, hi, func(point Point) {
+		result[point.DocId]++
+	})
+	return result
+}
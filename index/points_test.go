@@ -0,0 +1,44 @@
+package index
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPoints(t *testing.T) {
+	p := &Points{}
+	// added in order
+	for i := 0; i < 5; i++ {
+		p.insert(i, PointKey{Hi: uint64(i)}, 0)
+	}
+	assert.Equal(t, NumericResult{1: 1, 2: 1, 3: 1}, p.keyRange(PointKey{Hi: 1}, PointKey{Hi: 3}))
+
+	// added out of order
+	rnd := rand.New(rand.NewSource(1))
+	keys := []PointKey{{Hi: 0}, {Hi: 1}, {Hi: 2}, {Hi: 3}, {Hi: 4}}
+	for i := 5; i < 1000; i++ {
+		key := PointKey{Hi: uint64(rnd.Intn(100)), Lo: uint64(rnd.Intn(2))}
+		keys = append(keys, key)
+		p.insert(i, key, i)
+	}
+	assert.Equal(t, 1000, p.Len())
+	points := p.All()
+	assert.True(t, sort.SliceIsSorted(points, func(i, j int) bool {
+		return pointLess(points[i], points[j])
+	}))
+	for _, point := range points {
+		assert.Equal(t, keys[point.DocId], point.Key)
+	}
+
+	// ranges compare Hi then Lo
+	want := NumericResult{}
+	for docId, key := range keys {
+		if (key.Hi == 10 && key.Lo == 1) || key.Hi == 11 || (key.Hi == 12 && key.Lo == 0) {
+			want[docId]++
+		}
+	}
+	assert.Equal(t, want, p.keyRange(PointKey{Hi: 10, Lo: 1}, PointKey{Hi: 12, Lo: 0}))
+	assert.Equal(t, NumericResult{}, p.keyRange(PointKey{Hi: 12}, PointKey{Hi: 10}))
+}
@@ -0,0 +1,78 @@
+package inverted
+
+import (
+	"errors"
+	"github.com/richardjennings/invertedindex/analyser"
+)
+
+// AnalyseRequest is the text to analyse and the analysis chain to use.
+// The chain is that of Field, the analyser named by Analyser or a custom
+// chain of CharFilters, Tokenizer and Filters in that order of precedence,
+// otherwise the default analyser
+type AnalyseRequest struct {
+	Text        string   `json:"text"`
+	Field       string   `json:"field"`
+	Analyser    string   `json:"analyzer"`
+	CharFilters []string `json:"char_filter"`
+	Tokenizer   string   `json:"tokenizer"`
+	Filters     []string `json:"filter"`
+}
+
+// AnalysedToken is a token produced by an analyse request
+type AnalysedToken struct {
+	Token       string `json:"token"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	Type        string `json:"type"`
+	Position    int    `json:"position"`
+}
+
+// Analyse returns the tokens produced by the analysis chain of req. Analysis
+// components defined in the settings of indexName may be used, indexName
+// may be empty when only built-in components are used
+func (e *Engine) Analyse(indexName string, req *AnalyseRequest) ([]AnalysedToken, error) {
+	var analysis analyser.Analysis
+	var a *analyser.FullTextAnalyser
+	var err error
+	if indexName != "" {
+		cidx, err := e.GetIndex(indexName)
+		if err != nil {
+			return nil, err
+		}
+		analysis = cidx.Analysis
+		if req.Field != "" {
+			a, err = cidx.FieldAnalyser(req.Field)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else if req.Field != "" {
+		return nil, errors.New("field requires an index")
+	}
+	if a == nil {
+		switch {
+		case req.Analyser != "":
+			a, err = analysis.NewAnalyser(req.Analyser, nil)
+		case req.Tokenizer != "" || len(req.CharFilters) > 0 || len(req.Filters) > 0:
+			a, err = analysis.NewAnalyser("custom", analyser.Config{
+				"char_filter": req.CharFilters,
+				"tokenizer":   req.Tokenizer,
+				"filter":      req.Filters,
+			})
+		default:
+			a, err = analysis.NewAnalyser(analyser.DefaultAnalyser, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	tokens, err := a.Analyse(req.Text)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AnalysedToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = AnalysedToken{Token: t.Term, StartOffset: t.Start, EndOffset: t.End, Type: t.Type, Position: t.Position}
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package inverted
+
+import (
+	"errors"
+	"github.com/richardjennings/invertedindex/analyser"
+	"github.com/richardjennings/invertedindex/index"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEngine_Analyse(t *testing.T) {
+	e := New()
+	_, err := e.NewIndexWithSettings("test", map[string]map[string]string{
+		"title": {"type": "text", "analyzer": "folded"},
+		"tag":   {"type": "keyword"},
+	}, index.Settings{Analysis: analyser.Analysis{
+		Analysers: map[string]analyser.Config{"folded": {"tokenizer": "whitespace", "filter": "asciifolding"}},
+	}})
+	assert.Nil(t, err)
+
+	tcases := []struct {
+		name      string
+		indexName string
+		req       AnalyseRequest
+		want      []AnalysedToken
+		wantErr   error
+	}{
+		{
+			"default analyser",
+			"",
+			AnalyseRequest{Text: "The Cat"},
+			[]AnalysedToken{
+				{Token: "the", StartOffset: 0, EndOffset: 3, Type: analyser.TypeAlphaNum, Position: 0},
+				{Token: "cat", StartOffset: 4, EndOffset: 7, Type: analyser.TypeAlphaNum, Position: 1},
+			},
+			nil,
+		},
+		{
+			"named analyser",
+			"",
+			AnalyseRequest{Text: "The Cat", Analyser: "stop"},
+			[]AnalysedToken{{Token: "cat", StartOffset: 4, EndOffset: 7, Type: analyser.TypeAlphaNum, Position: 1}},
+			nil,
+		},
+		{
+			"custom chain",
+			"",
+			AnalyseRequest{Text: "The Cat", Tokenizer: "whitespace", Filters: []string{"lowercase"}},
+			[]AnalysedToken{
+				{Token: "the", StartOffset: 0, EndOffset: 3, Type: analyser.TypeWord, Position: 0},
+				{Token: "cat", StartOffset: 4, EndOffset: 7, Type: analyser.TypeWord, Position: 1},
+			},
+			nil,
+		},
+		{
+			"index analyser",
+			"test",
+			AnalyseRequest{Text: "Café", Analyser: "folded"},
+			[]AnalysedToken{{Token: "Cafe", StartOffset: 0, EndOffset: 5, Type: analyser.TypeWord, Position: 0}},
+			nil,
+		},
+		{
+			"field analyser",
+			"test",
+			AnalyseRequest{Text: "Café", Field: "title"},
+			[]AnalysedToken{{Token: "Cafe", StartOffset: 0, EndOffset: 5, Type: analyser.TypeWord, Position: 0}},
+			nil,
+		},
+		{"index analyser without index", "", AnalyseRequest{Analyser: "folded"}, nil, errors.New("unknown analyser folded")},
+		{"field without index", "", AnalyseRequest{Field: "title"}, nil, errors.New("field requires an index")},
+		{"index not found", "notexists", AnalyseRequest{}, nil, errors.New(IndexNotFound)},
+		{"field not found", "test", AnalyseRequest{Field: "notexists"}, nil, errors.New("field not found")},
+		{"keyword field", "test", AnalyseRequest{Field: "tag"}, nil, errors.New("field does not support analysis")},
+		{"custom chain without tokenizer", "", AnalyseRequest{Filters: []string{"lowercase"}}, nil, errors.New("custom analyser requires a tokenizer")},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := e.Analyse(tc.indexName, &tc.req)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
@@ -17,6 +17,8 @@ type Engine struct {
 type SearchRequest struct {
 	Query *Query
 	Agg   *Aggregation
+	// Sort orders hits by each sort in turn
+	Sort []Sort
 }
 
 func New() Engine {
@@ -55,11 +57,15 @@ func (e *Engine) IndexStats(indexName string) (*index.Stats, error) {
 }
 
 func (e *Engine) NewIndex(indexName string, cf map[string]map[string]string) (*index.Index, error) {
+	return e.NewIndexWithSettings(indexName, cf, index.Settings{})
+}
+
+func (e *Engine) NewIndexWithSettings(indexName string, cf map[string]map[string]string, settings index.Settings) (*index.Index, error) {
 	_, exists := e.Indexes[indexName]
 	if exists {
 		return nil, errors.New(IndexAlreadyExists)
 	}
-	cidx, err := index.NewIndex(cf)
+	cidx, err := index.NewIndexWithSettings(cf, settings)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +102,14 @@ func (e *Engine) Search(idxName string, req *SearchRequest) (map[string][]int, e
 		if err != nil {
 			return nil, err
 		}
-		result["hits"] = res.Docs()
+		docs := res.Docs()
+		// stable sorts applied from the last order hits by the first
+		for i := len(req.Sort) - 1; i >= 0; i-- {
+			if err := req.Sort[i].Sort(cidx, docs); err != nil {
+				return nil, err
+			}
+		}
+		result["hits"] = docs
 	}
 	return result, nil
 }
@@ -196,7 +196,7 @@ func TestEngine_Query(t *testing.T) {
 			"genre":       "romance",
 		},
 	} {
-		err := e.Index("films", string(i+1), content)
+		err := e.Index("films", string(rune(i+1)), content)
 		assert.Nil(t, err)
 	}
 
@@ -225,7 +225,7 @@ func TestEngine_Query(t *testing.T) {
 			"match single term",
 			"films",
 			&SearchRequest{Query: &Query{Leaf: &MatchQuery{Field: "title", Term: "Godfather"}}},
-			map[string][]int{"hits": {1}},
+			map[string][]int{"hits": {1, 2}},
 			nil,
 		},
 		{
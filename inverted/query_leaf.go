@@ -15,10 +15,14 @@ type (
 	MatchQuery struct {
 		Field string
 		Term  string
+		// Analyser overrides the search analyser of the field when set
+		Analyser string
 	}
 	MatchPhraseQuery struct {
 		Field string
 		Term  string
+		// Analyser overrides the search analyser of the field when set
+		Analyser string
 	}
 	MultiMatchQuery struct {
 		Fields []string
@@ -36,8 +40,40 @@ type (
 		Field string
 		Terms []string
 	}
+	// RangeQuery bounds are nil when not set
+	RangeQuery struct {
+		Field string
+		Gt    interface{}
+		Gte   interface{}
+		Lt    interface{}
+		Lte   interface{}
+		// Format overrides the format of a date field for the bounds
+		Format string
+	}
+)
+
+// geo Leaf
+type (
+	GeoDistanceQuery struct {
+		Field  string
+		Origin index.LatLon
+		// Distance in metres
+		Distance float64
+	}
+	GeoBoundingBoxQuery struct {
+		Field       string
+		TopLeft     index.LatLon
+		BottomRight index.LatLon
+	}
 )
 
+// NestedQuery matches documents with an object of the nested field Path
+// matching Inner, whose fields are named by their full path
+type NestedQuery struct {
+	Path  string
+	Inner *Query
+}
+
 type QueryResult map[int]struct{}
 
 func (q QueryResult) Docs() []int {
@@ -59,6 +95,13 @@ func (m MatchQuery) Query(cidx *index.Index) (index.Result, error) {
 	if !ok {
 		return nil, errors.New("field does not support match queries")
 	}
+	if m.Analyser != "" {
+		a, err := cidx.Analysis.NewAnalyser(m.Analyser, nil)
+		if err != nil {
+			return nil, err
+		}
+		return idx.(index.Match).MatchQueryWithAnalyser(m.Term, a)
+	}
 	return idx.(index.Match).MatchQuery(m.Term)
 }
 
@@ -71,6 +114,13 @@ func (m MatchPhraseQuery) Query(cidx *index.Index) (index.Result, error) {
 	if !ok {
 		return nil, errors.New("field does not support match phrase queries")
 	}
+	if m.Analyser != "" {
+		a, err := cidx.Analysis.NewAnalyser(m.Analyser, nil)
+		if err != nil {
+			return nil, err
+		}
+		return idx.(index.Phrase).PhraseQueryWithAnalyser(m.Term, a)
+	}
 	return idx.(index.Phrase).PhraseQuery(m.Term)
 }
 
@@ -121,3 +171,56 @@ func (m TermsQuery) Query(cidx *index.Index) (index.Result, error) {
 	}
 	return idx.(index.Terms).TermsQuery(m.Terms)
 }
+
+func (m RangeQuery) Query(cidx *index.Index) (index.Result, error) {
+	idx, err := cidx.GetFieldIdx(m.Field)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := idx.(index.Range)
+	if !ok {
+		return nil, errors.New("field does not support range queries")
+	}
+	return idx.(index.Range).RangeQuery(index.Bounds{Gt: m.Gt, Gte: m.Gte, Lt: m.Lt, Lte: m.Lte, Format: m.Format})
+}
+
+func (m GeoDistanceQuery) Query(cidx *index.Index) (index.Result, error) {
+	idx, err := cidx.GetFieldIdx(m.Field)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := idx.(index.GeoDistance)
+	if !ok {
+		return nil, errors.New("field does not support geo_distance queries")
+	}
+	return idx.(index.GeoDistance).GeoDistanceQuery(m.Origin, m.Distance)
+}
+
+func (m GeoBoundingBoxQuery) Query(cidx *index.Index) (index.Result, error) {
+	idx, err := cidx.GetFieldIdx(m.Field)
+	if err != nil {
+		return nil, err
+	}
+	_, ok := idx.(index.GeoBoundingBox)
+	if !ok {
+		return nil, errors.New("field does not support geo_bounding_box queries")
+	}
+	return idx.(index.GeoBoundingBox).GeoBoundingBoxQuery(m.TopLeft, m.BottomRight)
+}
+
+func (m NestedQuery) Query(cidx *index.Index) (index.Result, error) {
+	nested, err := cidx.GetNested(m.Path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := m.Inner.Run(nested.Index)
+	if err != nil {
+		return nil, err
+	}
+	result := QueryResult{}
+	var t struct{}
+	for _, d := range r.Docs() {
+		result[nested.Parents[d]] = t
+	}
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/richardjennings/invertedindex/index"
 	"github.com/stretchr/testify/assert"
+	"strconv"
 	"testing"
 )
 
@@ -14,24 +15,68 @@ func TestMatchQuery_Query(t *testing.T) {
 	assert.Nil(t, err)
 
 	// query result
-	q := MatchQuery{"test", "some"}
+	q := MatchQuery{Field: "test", Term: "some"}
 	r, err := q.Query(cidx)
 	assert.Nil(t, err)
 	assert.Equal(t, index.TermFreqResult{0: {1}}, r)
 
+	// query analyser override
+	q = MatchQuery{Field: "test", Term: "Some", Analyser: "whitespace"}
+	r, err = q.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.TermFreqResult{}, r)
+
+	// error unknown analyser
+	q = MatchQuery{Field: "test", Term: "some", Analyser: "magic"}
+	r, err = q.Query(cidx)
+	assert.Nil(t, r)
+	assert.Equal(t, errors.New("unknown analyser magic"), err)
+
 	// error index not exists
-	q = MatchQuery{"notexists", "some"}
+	q = MatchQuery{Field: "notexists", Term: "some"}
 	r, err = q.Query(cidx)
 	assert.Nil(t, r)
 	assert.Equal(t, errors.New("field not found"), err)
 
 	// error field does not support match queries
-	q = MatchQuery{"test2", "some"}
+	q = MatchQuery{Field: "test2", Term: "some"}
 	r, err = q.Query(cidx)
 	assert.Nil(t, r)
 	assert.Equal(t, errors.New("field does not support match queries"), err)
 }
 
+// test the analyzer and search_analyzer of a field and the analyser of a
+// query change the terms indexed and queried
+func TestMatchQuery_Analysers(t *testing.T) {
+	cidx, err := index.NewIndex(map[string]map[string]string{
+		"exact":  {"type": "text", "analyzer": "whitespace"},
+		"folded": {"type": "text", "analyzer": "whitespace", "search_analyzer": "standard"},
+	})
+	assert.Nil(t, err)
+	err = cidx.Index("0", map[string]interface{}{"exact": "Some Content", "folded": "some Content"})
+	assert.Nil(t, err)
+
+	for _, tcase := range []struct {
+		name string
+		q    LeafQuery
+		want index.Result
+	}{
+		{"analyzer keeps case", MatchQuery{Field: "exact", Term: "Some"}, index.TermFreqResult{0: {1}}},
+		{"analyzer does not lowercase", MatchQuery{Field: "exact", Term: "some"}, index.TermFreqResult{}},
+		{"search analyzer lowercases query", MatchQuery{Field: "folded", Term: "SOME"}, index.TermFreqResult{0: {1}}},
+		{"search analyzer misses indexed case", MatchQuery{Field: "folded", Term: "Content"}, index.TermFreqResult{}},
+		{"query analyser overrides search analyzer", MatchQuery{Field: "folded", Term: "Content", Analyser: "whitespace"}, index.TermFreqResult{0: {1}}},
+		{"query analyser overrides analyzer", MatchQuery{Field: "exact", Term: "SOME", Analyser: "standard"}, index.TermFreqResult{}},
+		{"phrase analyzer keeps case", MatchPhraseQuery{Field: "exact", Term: "Some Content"}, index.PostingResult{0: {0}}},
+		{"phrase search analyzer", MatchPhraseQuery{Field: "folded", Term: "SOME"}, index.PostingResult{0: {0}}},
+		{"phrase query analyser", MatchPhraseQuery{Field: "folded", Term: "some Content", Analyser: "whitespace"}, index.PostingResult{0: {0}}},
+	} {
+		r, err := tcase.q.Query(cidx)
+		assert.Nil(t, err, tcase.name)
+		assert.Equal(t, tcase.want, r, tcase.name)
+	}
+}
+
 func TestMatchPhraseQuery_Query(t *testing.T) {
 	cidx, err := index.NewIndex(map[string]map[string]string{"test": {"type": "text"}, "test2": {"type": "keyword"}})
 	assert.Nil(t, err)
@@ -39,19 +84,31 @@ func TestMatchPhraseQuery_Query(t *testing.T) {
 	assert.Nil(t, err)
 
 	// query result
-	q := MatchPhraseQuery{"test", "is some"}
+	q := MatchPhraseQuery{Field: "test", Term: "is some"}
 	r, err := q.Query(cidx)
 	assert.Nil(t, err)
 	assert.Equal(t, index.PostingResult{0: {1}}, r)
 
+	// query analyser override
+	q = MatchPhraseQuery{Field: "test", Term: "Is Some", Analyser: "whitespace"}
+	r, err = q.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.PostingResult{}, r)
+
+	// error unknown analyser
+	q = MatchPhraseQuery{Field: "test", Term: "is some", Analyser: "magic"}
+	r, err = q.Query(cidx)
+	assert.Nil(t, r)
+	assert.Equal(t, errors.New("unknown analyser magic"), err)
+
 	// error index not exists
-	q = MatchPhraseQuery{"notexists", "is some"}
+	q = MatchPhraseQuery{Field: "notexists", Term: "is some"}
 	r, err = q.Query(cidx)
 	assert.Nil(t, r)
 	assert.Equal(t, errors.New("field not found"), err)
 
 	// error field does not support match queries
-	q = MatchPhraseQuery{"test2", "is some"}
+	q = MatchPhraseQuery{Field: "test2", Term: "is some"}
 	r, err = q.Query(cidx)
 	assert.Nil(t, r)
 	assert.Equal(t, errors.New("field does not support match phrase queries"), err)
@@ -160,6 +217,129 @@ func TestTermsQuery_Query(t *testing.T) {
 	assert.Equal(t, errors.New("field does not support terms queries"), err)
 }
 
+func TestRangeQuery_Query(t *testing.T) {
+	cidx, err := index.NewIndex(map[string]map[string]string{"price": {"type": "double"}, "test": {"type": "keyword"}})
+	assert.Nil(t, err)
+	err = cidx.Index("0", map[string]interface{}{"price": 9.99})
+	assert.Nil(t, err)
+	err = cidx.Index("1", map[string]interface{}{"price": 20.0})
+	assert.Nil(t, err)
+
+	// query result
+	q := RangeQuery{Field: "price", Gte: float64(10)}
+	r, err := q.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.NumericResult{1: 1}, r)
+	q = RangeQuery{Field: "price", Gt: "5", Lt: float64(20)}
+	r, err = q.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.NumericResult{0: 1}, r)
+
+	// error index not exists
+	q = RangeQuery{Field: "notexists", Gt: float64(1)}
+	r, err = q.Query(cidx)
+	assert.Nil(t, r)
+	assert.Equal(t, errors.New("field not found"), err)
+
+	// error field does not support range queries
+	q = RangeQuery{Field: "test", Gt: float64(1)}
+	r, err = q.Query(cidx)
+	assert.Nil(t, r)
+	assert.Equal(t, errors.New("field does not support range queries"), err)
+}
+
+func TestGeoQueries_Query(t *testing.T) {
+	cidx, err := index.NewIndex(map[string]map[string]string{"location": {"type": "geo_point"}, "test": {"type": "keyword"}})
+	assert.Nil(t, err)
+	err = cidx.Index("london", map[string]interface{}{"location": "51.5074,-0.1278"})
+	assert.Nil(t, err)
+	err = cidx.Index("paris", map[string]interface{}{"location": "48.8566,2.3522"})
+	assert.Nil(t, err)
+
+	// query result
+	d := GeoDistanceQuery{Field: "location", Origin: index.LatLon{Lat: 51.5, Lon: 0}, Distance: 100000}
+	r, err := d.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.NumericResult{0: 1}, r)
+	b := GeoBoundingBoxQuery{Field: "location", TopLeft: index.LatLon{Lat: 50, Lon: 0}, BottomRight: index.LatLon{Lat: 48, Lon: 3}}
+	r, err = b.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, index.NumericResult{1: 1}, r)
+
+	// error index not exists
+	d.Field = "notexists"
+	_, err = d.Query(cidx)
+	assert.Equal(t, errors.New("field not found"), err)
+	b.Field = "notexists"
+	_, err = b.Query(cidx)
+	assert.Equal(t, errors.New("field not found"), err)
+
+	// error field does not support geo queries
+	d.Field = "test"
+	_, err = d.Query(cidx)
+	assert.Equal(t, errors.New("field does not support geo_distance queries"), err)
+	b.Field = "test"
+	_, err = b.Query(cidx)
+	assert.Equal(t, errors.New("field does not support geo_bounding_box queries"), err)
+}
+
+func TestNestedQuery_Query(t *testing.T) {
+	cidx, err := index.NewIndex(map[string]map[string]string{
+		"comments":        {"type": "nested"},
+		"comments.author": {"type": "keyword"},
+		"comments.stars":  {"type": "integer"},
+		"reviews.author":  {"type": "keyword"},
+		"reviews.stars":   {"type": "integer"},
+	})
+	assert.Nil(t, err)
+	for _, doc := range []map[string]interface{}{
+		{
+			"comments": []interface{}{
+				map[string]interface{}{"author": "alice", "stars": 5.0},
+				map[string]interface{}{"author": "bob", "stars": 1.0},
+			},
+			"reviews": []interface{}{
+				map[string]interface{}{"author": "alice", "stars": 5.0},
+				map[string]interface{}{"author": "bob", "stars": 1.0},
+			},
+		},
+		{
+			"comments": map[string]interface{}{"author": "alice", "stars": 1.0},
+			"reviews":  map[string]interface{}{"author": "alice", "stars": 1.0},
+		},
+	} {
+		err = cidx.Index(strconv.Itoa(len(cidx.Documents)), doc)
+		assert.Nil(t, err)
+	}
+	aliceOneStar := func(prefix string) *Query {
+		return &Query{BoolMust: []*BoolMustQuery{
+			{Query: &Query{Leaf: &TermQuery{Field: prefix + ".author", Term: "alice"}}},
+			{Query: &Query{Leaf: &RangeQuery{Field: prefix + ".stars", Lte: float64(1)}}},
+		}}
+	}
+
+	// conditions match within the same object
+	q := NestedQuery{Path: "comments", Inner: aliceOneStar("comments")}
+	r, err := q.Query(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, r.Docs())
+
+	// unlike the flattened values of an array of objects
+	r, err = aliceOneStar("reviews").Run(cidx)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1}, r.Docs())
+
+	// error nested path not found
+	q = NestedQuery{Path: "reviews", Inner: aliceOneStar("reviews")}
+	_, err = q.Query(cidx)
+	assert.Equal(t, errors.New("nested path not found"), err)
+
+	// error in inner query
+	q = NestedQuery{Path: "comments", Inner: &Query{Leaf: &TermQuery{Field: "author", Term: "alice"}}}
+	_, err = q.Query(cidx)
+	assert.Equal(t, errors.New("field not found"), err)
+}
+
 func TestQueryResult_Docs(t *testing.T) {
 	var s struct{}
 	q := QueryResult{1: s, 2: s, 3: s, 4: s}
@@ -0,0 +1,46 @@
+package inverted
+
+import (
+	"errors"
+	"github.com/richardjennings/invertedindex/index"
+	"sort"
+)
+
+// Sort orders the hits of a search in place, keeping the order of hits
+// that are equal
+type Sort interface {
+	Sort(cidx *index.Index, docs []int) error
+}
+
+// GeoDistanceSort orders hits by the distance of the nearest location of
+// Field from Origin, nearest first unless Desc is set. Hits without a
+// location are last
+type GeoDistanceSort struct {
+	Field  string
+	Origin index.LatLon
+	Desc   bool
+}
+
+func (s GeoDistanceSort) Sort(cidx *index.Index, docs []int) error {
+	idx, err := cidx.GetFieldIdx(s.Field)
+	if err != nil {
+		return err
+	}
+	_, ok := idx.(index.GeoDistance)
+	if !ok {
+		return errors.New("field does not support geo distance sorting")
+	}
+	distances := idx.(index.GeoDistance).GeoDistances(s.Origin)
+	sort.SliceStable(docs, func(i, j int) bool {
+		di, iok := distances[docs[i]]
+		dj, jok := distances[docs[j]]
+		if !iok || !jok {
+			return iok && !jok
+		}
+		if s.Desc {
+			return di > dj
+		}
+		return di < dj
+	})
+	return nil
+}
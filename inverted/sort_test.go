@@ -0,0 +1,46 @@
+package inverted
+
+import (
+	"errors"
+	"github.com/richardjennings/invertedindex/index"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGeoDistanceSort_Sort(t *testing.T) {
+	e := New()
+	_, err := e.NewIndex("stores", map[string]map[string]string{"location": {"type": "geo_point"}, "name": {"type": "keyword"}})
+	assert.Nil(t, err)
+	for _, store := range []map[string]interface{}{
+		{"name": "paris", "location": "48.8566,2.3522"},
+		{"name": "london", "location": "51.5074,-0.1278"},
+		{"name": "online"},
+		{"name": "amsterdam", "location": []interface{}{"52.3676,4.9041", "40.7128,-74.0060"}},
+	} {
+		err = e.Index("stores", store["name"].(string), store)
+		assert.Nil(t, err)
+	}
+	all := &Query{Leaf: &TermsQuery{Field: "name", Terms: []string{"paris", "london", "online", "amsterdam"}}}
+	origin := index.LatLon{Lat: 52, Lon: 0}
+
+	r, err := e.Search("stores", &SearchRequest{Query: all, Sort: []Sort{GeoDistanceSort{Field: "location", Origin: origin}}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]int{"hits": {1, 3, 0, 2}}, r)
+
+	r, err = e.Search("stores", &SearchRequest{Query: all, Sort: []Sort{GeoDistanceSort{Field: "location", Origin: origin, Desc: true}}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]int{"hits": {0, 3, 1, 2}}, r)
+
+	// the first sort orders hits before the second
+	r, err = e.Search("stores", &SearchRequest{Query: all, Sort: []Sort{
+		GeoDistanceSort{Field: "location", Origin: index.LatLon{Lat: 40, Lon: -74}},
+		GeoDistanceSort{Field: "location", Origin: origin},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]int{"hits": {3, 1, 0, 2}}, r)
+
+	_, err = e.Search("stores", &SearchRequest{Query: all, Sort: []Sort{GeoDistanceSort{Field: "name", Origin: origin}}})
+	assert.Equal(t, errors.New("field does not support geo distance sorting"), err)
+	_, err = e.Search("stores", &SearchRequest{Query: all, Sort: []Sort{GeoDistanceSort{Field: "notexists", Origin: origin}}})
+	assert.Equal(t, errors.New("field not found"), err)
+}
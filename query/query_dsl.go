@@ -1,9 +1,12 @@
 package query
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/richardjennings/invertedindex/index"
 	"github.com/richardjennings/invertedindex/inverted"
+	"strconv"
 )
 
 // JSON request parsing
@@ -11,10 +14,17 @@ func ParseReq(q []byte) (*inverted.SearchRequest, error) {
 
 	req := &inverted.SearchRequest{}
 	var i interface{}
-	err := json.Unmarshal(q, &i)
+	// numbers are decoded as json.Number, as documents are, so that values
+	// such as longs above 2^53 are queried exactly
+	d := json.NewDecoder(bytes.NewReader(q))
+	d.UseNumber()
+	err := d.Decode(&i)
 	if err != nil {
 		return nil, err
 	}
+	if d.More() {
+		return nil, errors.New("unexpected data after query")
+	}
 	switch a := i.(type) {
 	case map[string]interface{}:
 
@@ -31,6 +41,13 @@ func ParseReq(q []byte) (*inverted.SearchRequest, error) {
 				return nil, err
 			}
 		}
+
+		if s, ok := a["sort"]; ok {
+			req.Sort, err = parseSort(s)
+			if err != nil {
+				return nil, err
+			}
+		}
 	default:
 		return nil, errors.New("unknown type")
 	}
@@ -92,6 +109,30 @@ func parseQuery(i interface{}) (*inverted.Query, error) {
 				return nil, err
 			}
 			return &inverted.Query{Leaf: q}, nil
+		case "range":
+			q, err := parseRangeQuery(j)
+			if err != nil {
+				return nil, err
+			}
+			return &inverted.Query{Leaf: q}, nil
+		case "nested":
+			q, err := parseNestedQuery(j)
+			if err != nil {
+				return nil, err
+			}
+			return &inverted.Query{Leaf: q}, nil
+		case "geo_distance":
+			q, err := parseGeoDistanceQuery(j)
+			if err != nil {
+				return nil, err
+			}
+			return &inverted.Query{Leaf: q}, nil
+		case "geo_bounding_box":
+			q, err := parseGeoBoundingBoxQuery(j)
+			if err != nil {
+				return nil, err
+			}
+			return &inverted.Query{Leaf: q}, nil
 		case "multi_match":
 			q, err := parseMultiMatchQuery(j)
 			if err != nil {
@@ -122,6 +163,14 @@ func parseMatchPhraseQuery(a map[string]interface{}) (*inverted.MatchPhraseQuery
 			default:
 				return nil, errors.New("expected string")
 			}
+			if analyser, ok := j["analyzer"]; ok {
+				switch analyser.(type) {
+				case string:
+					q.Analyser = analyser.(string)
+				default:
+					return nil, errors.New("expected string")
+				}
+			}
 		case string:
 			q.Term = j
 		default:
@@ -181,6 +230,14 @@ func parseMatchQuery(a map[string]interface{}) (*inverted.MatchQuery, error) {
 			default:
 				return nil, errors.New("expected string")
 			}
+			if analyser, ok := j["analyzer"]; ok {
+				switch analyser.(type) {
+				case string:
+					q.Analyser = analyser.(string)
+				default:
+					return nil, errors.New("expected string")
+				}
+			}
 		case string:
 			q.Term = j
 		default:
@@ -198,6 +255,12 @@ func parseTermQuery(a map[string]interface{}) (*inverted.TermQuery, error) {
 		switch v.(type) {
 		case string:
 			q.Term = v.(string)
+		case json.Number:
+			// the value of a numeric field as given
+			q.Term = v.(json.Number).String()
+		case bool:
+			// the value of a boolean field
+			q.Term = strconv.FormatBool(v.(bool))
 		default:
 			return nil, errors.New("expected string")
 		}
@@ -205,6 +268,138 @@ func parseTermQuery(a map[string]interface{}) (*inverted.TermQuery, error) {
 	return &q, nil
 }
 
+// JSON range query parsing
+func parseRangeQuery(a map[string]interface{}) (*inverted.RangeQuery, error) {
+	q := inverted.RangeQuery{}
+	for k, v := range a {
+		q.Field = k
+		j, err := mapStrI(v)
+		if err != nil {
+			return nil, err
+		}
+		for op, bound := range j {
+			if op == "format" {
+				format, ok := bound.(string)
+				if !ok {
+					return nil, errors.New("expected string")
+				}
+				q.Format = format
+				continue
+			}
+			switch bound.(type) {
+			case json.Number, string:
+			default:
+				return nil, errors.New("expected number or string")
+			}
+			switch op {
+			case "gt":
+				q.Gt = bound
+			case "gte":
+				q.Gte = bound
+			case "lt":
+				q.Lt = bound
+			case "lte":
+				q.Lte = bound
+			default:
+				return nil, errors.New("unknown key")
+			}
+		}
+	}
+	return &q, nil
+}
+
+// JSON nested query parsing
+func parseNestedQuery(a map[string]interface{}) (*inverted.NestedQuery, error) {
+	var err error
+	q := inverted.NestedQuery{}
+	for k, v := range a {
+		switch k {
+		case "path":
+			switch v.(type) {
+			case string:
+				q.Path = v.(string)
+			default:
+				return nil, errors.New("expected string")
+			}
+		case "query":
+			q.Inner, err = parseQuery(v)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("unknown key")
+		}
+	}
+	if q.Path == "" {
+		return nil, errors.New("missing path")
+	}
+	if q.Inner == nil {
+		return nil, errors.New("missing query")
+	}
+	return &q, nil
+}
+
+// JSON geo_distance query parsing
+func parseGeoDistanceQuery(a map[string]interface{}) (*inverted.GeoDistanceQuery, error) {
+	var err error
+	q := inverted.GeoDistanceQuery{}
+	distance, ok := a["distance"]
+	if !ok {
+		return nil, errors.New("missing distance")
+	}
+	q.Distance, err = index.ParseDistance(distance)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range a {
+		if k == "distance" {
+			continue
+		}
+		if q.Field != "" {
+			return nil, errors.New("expected one field")
+		}
+		q.Field = k
+		q.Origin, err = index.ParseLatLon(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if q.Field == "" {
+		return nil, errors.New("missing field")
+	}
+	return &q, nil
+}
+
+// JSON geo_bounding_box query parsing
+func parseGeoBoundingBoxQuery(a map[string]interface{}) (*inverted.GeoBoundingBoxQuery, error) {
+	q := inverted.GeoBoundingBoxQuery{}
+	for k, v := range a {
+		q.Field = k
+		j, err := mapStrI(v)
+		if err != nil {
+			return nil, err
+		}
+		topLeft, ok := j["top_left"]
+		if !ok {
+			return nil, errors.New("missing top_left")
+		}
+		bottomRight, ok := j["bottom_right"]
+		if !ok {
+			return nil, errors.New("missing bottom_right")
+		}
+		if len(j) != 2 {
+			return nil, errors.New("unknown key")
+		}
+		if q.TopLeft, err = index.ParseLatLon(topLeft); err != nil {
+			return nil, err
+		}
+		if q.BottomRight, err = index.ParseLatLon(bottomRight); err != nil {
+			return nil, err
+		}
+	}
+	return &q, nil
+}
+
 // JSON terms query parsing
 func parseTermsQuery(a map[string]interface{}) (*inverted.TermsQuery, error) {
 	q := inverted.TermsQuery{}
@@ -212,7 +407,16 @@ func parseTermsQuery(a map[string]interface{}) (*inverted.TermsQuery, error) {
 		switch terms := v.(type) {
 		case []interface{}:
 			for _, t := range terms {
-				q.Terms = append(q.Terms, t.(string))
+				switch t.(type) {
+				case string:
+					q.Terms = append(q.Terms, t.(string))
+				case json.Number:
+					q.Terms = append(q.Terms, t.(json.Number).String())
+				case bool:
+					q.Terms = append(q.Terms, strconv.FormatBool(t.(bool)))
+				default:
+					return nil, errors.New("expected string")
+				}
 			}
 		default:
 			return nil, errors.New("invalid value for terms")
@@ -361,3 +565,72 @@ func parseBoolQuery(a map[string]interface{}) (*inverted.Query, error) {
 	}
 	return &q, nil
 }
+
+// JSON sort parsing, a sort or an array of sorts
+func parseSort(i interface{}) ([]inverted.Sort, error) {
+	sorts, ok := i.([]interface{})
+	if !ok {
+		sorts = []interface{}{i}
+	}
+	var result []inverted.Sort
+	for _, s := range sorts {
+		a, err := mapStrI(s)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range a {
+			switch k {
+			case "_geo_distance":
+				j, err := mapStrI(v)
+				if err != nil {
+					return nil, err
+				}
+				sort, err := parseGeoDistanceSort(j)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, sort)
+			default:
+				return nil, errors.New("unsupported sort")
+			}
+		}
+	}
+	return result, nil
+}
+
+// JSON _geo_distance sort parsing
+func parseGeoDistanceSort(a map[string]interface{}) (*inverted.GeoDistanceSort, error) {
+	var err error
+	s := inverted.GeoDistanceSort{}
+	for k, v := range a {
+		switch k {
+		case "order":
+			switch v {
+			case "asc":
+			case "desc":
+				s.Desc = true
+			default:
+				return nil, errors.New("order must be asc or desc")
+			}
+		case "unit":
+			// hits are returned without their distance so the unit
+			// does not change the order
+			if _, ok := v.(string); !ok {
+				return nil, errors.New("expected string")
+			}
+		default:
+			if s.Field != "" {
+				return nil, errors.New("expected one field")
+			}
+			s.Field = k
+			s.Origin, err = index.ParseLatLon(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.Field == "" {
+		return nil, errors.New("missing field")
+	}
+	return &s, nil
+}
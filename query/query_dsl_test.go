@@ -1,7 +1,9 @@
 package query
 
 import (
+	"encoding/json"
 	"errors"
+	"github.com/richardjennings/invertedindex/index"
 	"github.com/richardjennings/invertedindex/inverted"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -18,7 +20,13 @@ func TestParseReq(t *testing.T) {
 			"malformed json request",
 			`{`,
 			nil,
-			errors.New("unexpected end of JSON input"),
+			errors.New("unexpected EOF"),
+		},
+		{
+			"trailing data",
+			`{} {}`,
+			nil,
+			errors.New("unexpected data after query"),
 		},
 		{
 			"invalid json request",
@@ -64,7 +72,7 @@ func TestParseReq(t *testing.T) {
 		},
 		{
 			"invalid term Query type",
-			`{"query":{"term":{"true": true}}}`,
+			`{"query":{"term":{"true": ["a"]}}}`,
 			nil,
 			errors.New("expected string"),
 		},
@@ -391,6 +399,374 @@ func TestParseReq(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			"term number",
+			`{"query":{"term":{"a":1.5}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermQuery{
+						Field: "a",
+						Term:  "1.5",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"term number above 2^53",
+			`{"query":{"term":{"a":9007199254740993}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermQuery{
+						Field: "a",
+						Term:  "9007199254740993",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"term number as given",
+			`{"query":{"term":{"a":1.0}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermQuery{
+						Field: "a",
+						Term:  "1.0",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"term boolean",
+			`{"query":{"term":{"active":true}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermQuery{
+						Field: "active",
+						Term:  "true",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"terms mixed values",
+			`{"query":{"terms":{"a":["b", 2, false, 9007199254740993]}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermsQuery{
+						Field: "a",
+						Terms: []string{"b", "2", "false", "9007199254740993"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"invalid terms value",
+			`{"query":{"terms":{"a":[{}]}}}`,
+			nil,
+			errors.New("expected string"),
+		},
+		{
+			"nested",
+			`{"query":{"nested":{"path":"comments","query":{"term":{"comments.author":"alice"}}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.NestedQuery{
+						Path: "comments",
+						Inner: &inverted.Query{
+							Leaf: &inverted.TermQuery{
+								Field: "comments.author",
+								Term:  "alice",
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"nested missing path",
+			`{"query":{"nested":{"query":{"term":{"comments.author":"alice"}}}}}`,
+			nil,
+			errors.New("missing path"),
+		},
+		{
+			"nested missing query",
+			`{"query":{"nested":{"path":"comments"}}}`,
+			nil,
+			errors.New("missing query"),
+		},
+		{
+			"nested path not string",
+			`{"query":{"nested":{"path":1}}}`,
+			nil,
+			errors.New("expected string"),
+		},
+		{
+			"nested invalid query",
+			`{"query":{"nested":{"path":"comments","query":{"magic":{"a":"b"}}}}}`,
+			nil,
+			errors.New("unknown key"),
+		},
+		{
+			"nested unknown key",
+			`{"query":{"nested":{"score_mode":"avg"}}}`,
+			nil,
+			errors.New("unknown key"),
+		},
+		{
+			"geo_distance",
+			`{"query":{"geo_distance":{"distance":"12km","location":{"lat":51.5,"lon":-0.1}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.GeoDistanceQuery{
+						Field:    "location",
+						Origin:   index.LatLon{Lat: 51.5, Lon: -0.1},
+						Distance: 12000,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"geo_distance missing distance",
+			`{"query":{"geo_distance":{"location":"51.5,-0.1"}}}`,
+			nil,
+			errors.New("missing distance"),
+		},
+		{
+			"geo_distance invalid distance",
+			`{"query":{"geo_distance":{"distance":"12 leagues","location":"51.5,-0.1"}}}`,
+			nil,
+			errors.New("invalid distance 12 leagues"),
+		},
+		{
+			"geo_distance missing field",
+			`{"query":{"geo_distance":{"distance":"12km"}}}`,
+			nil,
+			errors.New("missing field"),
+		},
+		{
+			"geo_distance several fields",
+			`{"query":{"geo_distance":{"distance":"12km","a":"1,1","b":"2,2"}}}`,
+			nil,
+			errors.New("expected one field"),
+		},
+		{
+			"geo_distance invalid location",
+			`{"query":{"geo_distance":{"distance":"12km","location":"100,0"}}}`,
+			nil,
+			errors.New("invalid latitude 100"),
+		},
+		{
+			"geo_bounding_box",
+			`{"query":{"geo_bounding_box":{"location":{"top_left":"52,-1","bottom_right":{"lat":51,"lon":1}}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.GeoBoundingBoxQuery{
+						Field:       "location",
+						TopLeft:     index.LatLon{Lat: 52, Lon: -1},
+						BottomRight: index.LatLon{Lat: 51, Lon: 1},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"geo_bounding_box not map",
+			`{"query":{"geo_bounding_box":{"location":"52,-1"}}}`,
+			nil,
+			errors.New("expected map"),
+		},
+		{
+			"geo_bounding_box missing top_left",
+			`{"query":{"geo_bounding_box":{"location":{"bottom_right":"51,1"}}}}`,
+			nil,
+			errors.New("missing top_left"),
+		},
+		{
+			"geo_bounding_box missing bottom_right",
+			`{"query":{"geo_bounding_box":{"location":{"top_left":"52,-1"}}}}`,
+			nil,
+			errors.New("missing bottom_right"),
+		},
+		{
+			"geo_bounding_box unknown key",
+			`{"query":{"geo_bounding_box":{"location":{"top_left":"52,-1","bottom_right":"51,1","top":52}}}}`,
+			nil,
+			errors.New("unknown key"),
+		},
+		{
+			"geo_bounding_box invalid location",
+			`{"query":{"geo_bounding_box":{"location":{"top_left":"52,-1","bottom_right":"51,181"}}}}`,
+			nil,
+			errors.New("invalid longitude 181"),
+		},
+		{
+			"sort geo distance",
+			`{"query":{"term":{"a":"b"}},"sort":[{"_geo_distance":{"location":"51.5,-0.1","order":"desc","unit":"km"}}]}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.TermQuery{
+						Field: "a",
+						Term:  "b",
+					},
+				},
+				Sort: []inverted.Sort{
+					&inverted.GeoDistanceSort{
+						Field:  "location",
+						Origin: index.LatLon{Lat: 51.5, Lon: -0.1},
+						Desc:   true,
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"sort single",
+			`{"sort":{"_geo_distance":{"location":"u","order":"asc"}}}`,
+			&inverted.SearchRequest{
+				Sort: []inverted.Sort{
+					&inverted.GeoDistanceSort{
+						Field:  "location",
+						Origin: index.LatLon{Lat: 67.5, Lon: 22.5},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"sort unsupported",
+			`{"sort":[{"price":"asc"}]}`,
+			nil,
+			errors.New("unsupported sort"),
+		},
+		{
+			"sort not map",
+			`{"sort":["price"]}`,
+			nil,
+			errors.New("expected map"),
+		},
+		{
+			"sort geo distance not map",
+			`{"sort":{"_geo_distance":"51.5,-0.1"}}`,
+			nil,
+			errors.New("expected map"),
+		},
+		{
+			"sort geo distance invalid order",
+			`{"sort":{"_geo_distance":{"location":"51.5,-0.1","order":"up"}}}`,
+			nil,
+			errors.New("order must be asc or desc"),
+		},
+		{
+			"sort geo distance invalid unit",
+			`{"sort":{"_geo_distance":{"location":"51.5,-0.1","unit":1}}}`,
+			nil,
+			errors.New("expected string"),
+		},
+		{
+			"sort geo distance missing field",
+			`{"sort":{"_geo_distance":{"order":"asc"}}}`,
+			nil,
+			errors.New("missing field"),
+		},
+		{
+			"sort geo distance several fields",
+			`{"sort":{"_geo_distance":{"a":"1,1","b":"2,2"}}}`,
+			nil,
+			errors.New("expected one field"),
+		},
+		{
+			"sort geo distance invalid location",
+			`{"sort":{"_geo_distance":{"location":"x,1"}}}`,
+			nil,
+			errors.New("invalid location x,1"),
+		},
+		{
+			"range",
+			`{"query":{"range":{"price":{"gte":10,"lt":"20.5"}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.RangeQuery{
+						Field: "price",
+						Gte:   json.Number("10"),
+						Lt:    "20.5",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"range number above 2^53",
+			`{"query":{"range":{"stock":{"gt":9007199254740993}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.RangeQuery{
+						Field: "stock",
+						Gt:    json.Number("9007199254740993"),
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"range gt lte",
+			`{"query":{"range":{"price":{"gt":1,"lte":2}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.RangeQuery{
+						Field: "price",
+						Gt:    json.Number("1"),
+						Lte:   json.Number("2"),
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"range date math format",
+			`{"query":{"range":{"published":{"gte":"now-7d/d","lt":"01/02/2020","format":"dd/MM/yyyy"}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.RangeQuery{
+						Field:  "published",
+						Gte:    "now-7d/d",
+						Lt:     "01/02/2020",
+						Format: "dd/MM/yyyy",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"range invalid format",
+			`{"query":{"range":{"published":{"format":1}}}}`,
+			nil,
+			errors.New("expected string"),
+		},
+		{
+			"range not map",
+			`{"query":{"range":{"price":10}}}`,
+			nil,
+			errors.New("expected map"),
+		},
+		{
+			"range invalid bound",
+			`{"query":{"range":{"price":{"gt":true}}}}`,
+			nil,
+			errors.New("expected number or string"),
+		},
+		{
+			"range unknown key",
+			`{"query":{"range":{"price":{"from":1}}}}`,
+			nil,
+			errors.New("unknown key"),
+		},
 		{
 			"terms",
 			`{"query":{"terms":{"a":["b", "c"]}}}`,
@@ -456,6 +832,46 @@ func TestParseReq(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			"Match analyzer",
+			`{"query":{"match":{"field": {"query": "thequery", "analyzer": "whitespace"}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.MatchQuery{
+						Field:    "field",
+						Term:     "thequery",
+						Analyser: "whitespace",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"Match invalid analyzer",
+			`{"query":{"match":{"field": {"query": "thequery", "analyzer": 1}}}}`,
+			nil,
+			errors.New("expected string"),
+		},
+		{
+			"match_phrase analyzer",
+			`{"query":{"match_phrase":{"field": {"query": "thequery", "analyzer": "whitespace"}}}}`,
+			&inverted.SearchRequest{
+				Query: &inverted.Query{
+					Leaf: &inverted.MatchPhraseQuery{
+						Field:    "field",
+						Term:     "thequery",
+						Analyser: "whitespace",
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"match_phrase invalid analyzer",
+			`{"query":{"match_phrase":{"field": {"query": "thequery", "analyzer": 1}}}}`,
+			nil,
+			errors.New("expected string"),
+		},
 		{
 			"multi_match",
 			`{"query":{"multi_match":{"fields":["a","b"],"query": "thequery"}}}`,
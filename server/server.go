@@ -3,7 +3,9 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/pat"
+	"github.com/richardjennings/invertedindex/index"
 	"github.com/richardjennings/invertedindex/inverted"
 	"github.com/richardjennings/invertedindex/query"
 	"net/http"
@@ -52,6 +54,12 @@ func (a *httpApi) createMux() *http.ServeMux {
 	router.Get("/{name}/_search", a.search)
 	router.Post("/{name}/_search", a.search)
 
+	// analyse api
+	router.Get("/_analyze", a.analyse)
+	router.Post("/_analyze", a.analyse)
+	router.Get("/{name}/_analyze", a.analyse)
+	router.Post("/{name}/_analyze", a.analyse)
+
 	// index api
 
 	// index document with id and single field (plain text body)
@@ -77,7 +85,16 @@ func (a *httpApi) createMux() *http.ServeMux {
 	return mux
 }
 
+// badRequest is an error parsing a request
+type badRequest struct {
+	error
+}
+
 func (a *httpApi) handleError(err error, w http.ResponseWriter) {
+	if _, ok := err.(badRequest); ok {
+		w.WriteHeader(400)
+		return
+	}
 	switch err.Error() {
 	case "index not found":
 		w.WriteHeader(404)
@@ -155,6 +172,38 @@ func (a *httpApi) searchQueryString(w http.ResponseWriter, r *http.Request) {
 	a.jsonResponse(res, w)
 }
 
+// Show the tokens produced by an analyser, optionally of an index
+func (a *httpApi) analyse(w http.ResponseWriter, r *http.Request) {
+	indexName := r.URL.Query().Get(":name")
+	buf := new(bytes.Buffer)
+	_, err := buf.ReadFrom(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		a.handleError(err, w)
+		return
+	}
+	if buf.Len() == 0 {
+		a.handleError(badRequest{errors.New("missing request body")}, w)
+		return
+	}
+	req := &inverted.AnalyseRequest{}
+	err = json.Unmarshal(buf.Bytes(), req)
+	if err != nil {
+		a.handleError(badRequest{err}, w)
+		return
+	}
+	tokens, err := a.engine.Analyse(indexName, req)
+	if err != nil {
+		if err.Error() != "index not found" {
+			// the analysis chain or field named by the request is not known
+			err = badRequest{err}
+		}
+		a.handleError(err, w)
+		return
+	}
+	a.jsonResponse(map[string][]inverted.AnalysedToken{"tokens": tokens}, w)
+}
+
 // list all indexes
 func (a *httpApi) indexes(w http.ResponseWriter, r *http.Request) {
 	a.jsonResponse(a.engine.IndexList(), w)
@@ -175,7 +224,10 @@ func (a *httpApi) index(w http.ResponseWriter, r *http.Request) {
 func (a *httpApi) indexCreate(w http.ResponseWriter, r *http.Request) {
 	indexName := r.URL.Query().Get(":name")
 	// allow configuration using post body
-	cfg := make(map[string]map[string]map[string]string)
+	var cfg struct {
+		Mapping  index.Schema   `json:"mapping"`
+		Settings index.Settings `json:"settings"`
+	}
 	buf := new(bytes.Buffer)
 	_, err := buf.ReadFrom(r.Body)
 	if err != nil {
@@ -190,26 +242,8 @@ func (a *httpApi) indexCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// if there is config
-	conf, ok := cfg["mapping"]
-	if ok {
-		// create the index with the config
-		_, err := a.engine.NewIndex(indexName, conf)
-		if err != nil {
-			a.handleError(err, w)
-			return
-		}
-		stats, err := a.engine.IndexStats(indexName)
-		if err != nil {
-			a.handleError(err, w)
-			return
-		}
-		a.jsonResponse(stats, w)
-		return
-	}
-
-	// otherwise create index with no config
-	_, err = a.engine.NewIndex(indexName, nil)
+	// create the index with any config
+	_, err = a.engine.NewIndexWithSettings(indexName, cfg.Mapping, cfg.Settings)
 	if err != nil {
 		a.handleError(err, w)
 		return
@@ -243,8 +277,12 @@ func (a *httpApi) indexPutBody(w http.ResponseWriter, r *http.Request) {
 		a.handleError(err, w)
 		return
 	}
+	// numbers are decoded as json.Number so that integers are not
+	// rounded, and are indexed as strings by text and keyword fields
 	var content map[string]interface{}
-	err = json.Unmarshal(buf.Bytes(), &content)
+	d := json.NewDecoder(buf)
+	d.UseNumber()
+	err = d.Decode(&content)
 	if err != nil {
 		a.handleError(err, w)
 		return
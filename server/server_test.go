@@ -218,6 +218,390 @@ func TestServer_NewServer_HttpApi(t *testing.T) {
 			200,
 			`{"hits":[0]}`,
 		},
+		{
+			"create index with analysis settings",
+			"PUT",
+			"/settings",
+			bytes.NewBufferString(`{"mapping":{"a":{"type":"text","analyzer":"folded"}},"settings":{"analysis":{"analyzer":{"folded":{"tokenizer":"standard","filter":["lowercase","asciifolding"]}}}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"a":{"TermCount":0}}}`,
+		},
+		{
+			"index content with analysis settings",
+			"PUT",
+			"/settings/1",
+			bytes.NewBufferString(`{"a":"Crème Brûlée"}`),
+			200,
+			"true",
+		},
+		{
+			"match query with analysis settings",
+			"GET",
+			"/settings/_search",
+			bytes.NewBufferString(`{"query":{"match":{"a": "creme brulee"}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"create index with invalid analysis settings",
+			"PUT",
+			"/invalidsettings",
+			bytes.NewBufferString(`{"settings":{"analysis":{"analyzer":{"broken":{"tokenizer":"magic"}}}}}`),
+			500,
+			``,
+		},
+		{
+			"analyse",
+			"POST",
+			"/_analyze",
+			bytes.NewBufferString(`{"analyzer":"standard","text":"Hello, World"}`),
+			200,
+			`{"tokens":[{"token":"hello","start_offset":0,"end_offset":5,"type":"\u003cALPHANUM\u003e","position":0},{"token":"world","start_offset":7,"end_offset":12,"type":"\u003cALPHANUM\u003e","position":1}]}`,
+		},
+		{
+			"analyse index field",
+			"GET",
+			"/settings/_analyze",
+			bytes.NewBufferString(`{"field":"a","text":"Brûlée"}`),
+			200,
+			`{"tokens":[{"token":"brulee","start_offset":0,"end_offset":8,"type":"\u003cALPHANUM\u003e","position":0}]}`,
+		},
+		{
+			"analyse index not found",
+			"GET",
+			"/notexists/_analyze",
+			bytes.NewBufferString(`{"text":"hello"}`),
+			404,
+			``,
+		},
+		{
+			"analyse unknown analyser",
+			"GET",
+			"/_analyze",
+			bytes.NewBufferString(`{"analyzer":"magic","text":"hello"}`),
+			400,
+			``,
+		},
+		{
+			"analyse unknown tokenizer",
+			"GET",
+			"/_analyze",
+			bytes.NewBufferString(`{"tokenizer":"magic","filter":["lowercase"],"text":"hello"}`),
+			400,
+			``,
+		},
+		{
+			"analyse unknown filter",
+			"GET",
+			"/_analyze",
+			bytes.NewBufferString(`{"tokenizer":"standard","filter":["magic"],"text":"hello"}`),
+			400,
+			``,
+		},
+		{
+			"analyse unknown field",
+			"GET",
+			"/settings/_analyze",
+			bytes.NewBufferString(`{"field":"magic","text":"hello"}`),
+			400,
+			``,
+		},
+		{
+			"analyse invalid json",
+			"GET",
+			"/_analyze",
+			bytes.NewBufferString(`{"text":`),
+			400,
+			``,
+		},
+		{
+			"analyse empty body",
+			"POST",
+			"/_analyze",
+			bytes.NewBufferString(``),
+			400,
+			``,
+		},
+		{
+			"create index with numeric fields",
+			"PUT",
+			"/products",
+			bytes.NewBufferString(`{"mapping":{"price":{"type":"double"},"stock":{"type":"long"},"sku":{"type":"keyword"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"price":{"TermCount":0},"sku":{"TermCount":0},"stock":{"TermCount":0}}}`,
+		},
+		{
+			"index numbers from body",
+			"PUT",
+			"/products/1",
+			bytes.NewBufferString(`{"price":9.99,"stock":9007199254740993,"sku":1234}`),
+			200,
+			"true",
+		},
+		{
+			"index more numbers from body",
+			"PUT",
+			"/products/2",
+			bytes.NewBufferString(`{"price":25,"stock":3,"sku":"5678"}`),
+			200,
+			"true",
+		},
+		{
+			"range query",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"range":{"price":{"gte":10,"lt":50}}}}`),
+			200,
+			`{"hits":[1]}`,
+		},
+		{
+			"range query precise long",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"range":{"stock":{"gt":9007199254740992}}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"term query precise long",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"term":{"stock":9007199254740993}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"term query precise long not rounded",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"terms":{"stock":[9007199254740992,9007199254740994]}}}`),
+			200,
+			`{"hits":null}`,
+		},
+		{
+			"term query keyword as number",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"term":{"sku":1234}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"term query number as keyword",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"term":{"sku":"1234"}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"range query field does not support range",
+			"GET",
+			"/products/_search",
+			bytes.NewBufferString(`{"query":{"range":{"sku":{"gte":10}}}}`),
+			500,
+			``,
+		},
+		{
+			"create index with date field",
+			"PUT",
+			"/events",
+			bytes.NewBufferString(`{"mapping":{"at":{"type":"date","format":"strict_date_optional_time||epoch_millis"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"at":{"TermCount":0}}}`,
+		},
+		{
+			"index iso date",
+			"PUT",
+			"/events/1",
+			bytes.NewBufferString(`{"at":"2020-01-15T10:30:00Z"}`),
+			200,
+			"true",
+		},
+		{
+			"index epoch millis date",
+			"PUT",
+			"/events/2",
+			bytes.NewBufferString(`{"at":1583020800000}`),
+			200,
+			"true",
+		},
+		{
+			"range query date math",
+			"GET",
+			"/events/_search",
+			bytes.NewBufferString(`{"query":{"range":{"at":{"gte":"2020-01-01||/M","lte":"2020-01-01||/M"}}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"range query date format",
+			"GET",
+			"/events/_search",
+			bytes.NewBufferString(`{"query":{"range":{"at":{"gte":"01/03/2020","format":"dd/MM/yyyy"}}}}`),
+			200,
+			`{"hits":[1]}`,
+		},
+		{
+			"create index with boolean and ip fields",
+			"PUT",
+			"/access",
+			bytes.NewBufferString(`{"mapping":{"cached":{"type":"boolean"},"client":{"type":"ip"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"cached":{"TermCount":0},"client":{"TermCount":0}}}`,
+		},
+		{
+			"index boolean and ip",
+			"PUT",
+			"/access/1",
+			bytes.NewBufferString(`{"cached":true,"client":"10.1.2.3"}`),
+			200,
+			"true",
+		},
+		{
+			"index more boolean and ip",
+			"PUT",
+			"/access/2",
+			bytes.NewBufferString(`{"cached":false,"client":"192.168.1.20"}`),
+			200,
+			"true",
+		},
+		{
+			"index boolean into keyword field",
+			"PUT",
+			"/products/3",
+			bytes.NewBufferString(`{"sku":true}`),
+			500,
+			``,
+		},
+		{
+			"term query boolean",
+			"GET",
+			"/access/_search",
+			bytes.NewBufferString(`{"query":{"term":{"cached":false}}}`),
+			200,
+			`{"hits":[1]}`,
+		},
+		{
+			"term query cidr",
+			"GET",
+			"/access/_search",
+			bytes.NewBufferString(`{"query":{"term":{"client":"10.0.0.0/8"}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"create index with geo_point field",
+			"PUT",
+			"/stores",
+			bytes.NewBufferString(`{"mapping":{"location":{"type":"geo_point"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"location":{"TermCount":0}}}`,
+		},
+		{
+			"index lat lon object",
+			"PUT",
+			"/stores/paris",
+			bytes.NewBufferString(`{"location":{"lat":48.8566,"lon":2.3522}}`),
+			200,
+			"true",
+		},
+		{
+			"index geohash",
+			"PUT",
+			"/stores/london",
+			bytes.NewBufferString(`{"location":"gcpvj0duq"}`),
+			200,
+			"true",
+		},
+		{
+			"geo_distance query",
+			"GET",
+			"/stores/_search",
+			bytes.NewBufferString(`{"query":{"geo_distance":{"distance":"50km","location":"51.5,-0.1"}}}`),
+			200,
+			`{"hits":[1]}`,
+		},
+		{
+			"geo_bounding_box query sorted by distance",
+			"GET",
+			"/stores/_search",
+			bytes.NewBufferString(`{"query":{"geo_bounding_box":{"location":{"top_left":"60,-10","bottom_right":"40,10"}}},"sort":[{"_geo_distance":{"location":"51.5,-0.1"}}]}`),
+			200,
+			`{"hits":[1,0]}`,
+		},
+		{
+			"create index with object and nested fields",
+			"PUT",
+			"/posts",
+			bytes.NewBufferString(`{"mapping":{"user":{"type":"object"},"user.name":{"type":"keyword"},"comments":{"type":"nested"},"comments.author":{"type":"keyword"},"comments.stars":{"type":"integer"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"comments.author":{"TermCount":0},"comments.stars":{"TermCount":0},"user.name":{"TermCount":0}}}`,
+		},
+		{
+			"index object and nested",
+			"PUT",
+			"/posts/1",
+			bytes.NewBufferString(`{"user":{"name":"carol"},"comments":[{"author":"alice","stars":5},{"author":"bob","stars":1}]}`),
+			200,
+			"true",
+		},
+		{
+			"index more object and nested",
+			"PUT",
+			"/posts/2",
+			bytes.NewBufferString(`{"user":{"name":"dave"},"comments":[{"author":"alice","stars":1}]}`),
+			200,
+			"true",
+		},
+		{
+			"term query object field",
+			"GET",
+			"/posts/_search",
+			bytes.NewBufferString(`{"query":{"term":{"user.name":"carol"}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
+		{
+			"nested query",
+			"GET",
+			"/posts/_search",
+			bytes.NewBufferString(`{"query":{"nested":{"path":"comments","query":{"bool":{"must":[{"term":{"comments.author":"alice"}},{"range":{"comments.stars":{"lte":1}}}]}}}}}`),
+			200,
+			`{"hits":[1]}`,
+		},
+		{
+			"create index with text field",
+			"PUT",
+			"/notes",
+			bytes.NewBufferString(`{"mapping":{"tags":{"type":"text"}}}`),
+			200,
+			`{"DocumentCount":0,"Fields":{"tags":{"TermCount":0}}}`,
+		},
+		{
+			"index text array",
+			"PUT",
+			"/notes/1",
+			bytes.NewBufferString(`{"tags":["a b","c d"]}`),
+			200,
+			"true",
+		},
+		{
+			"match_phrase across values",
+			"GET",
+			"/notes/_search",
+			bytes.NewBufferString(`{"query":{"match_phrase":{"tags":"b c"}}}`),
+			200,
+			`{"hits":null}`,
+		},
+		{
+			"match_phrase within value",
+			"GET",
+			"/notes/_search",
+			bytes.NewBufferString(`{"query":{"match_phrase":{"tags":"c d"}}}`),
+			200,
+			`{"hits":[0]}`,
+		},
 		{
 			"query post body invalid json",
 			"GET",